@@ -1,7 +1,6 @@
 package app
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,6 +10,9 @@ import (
 	"github.com/risingwavelabs/events-api/app/zgen/apigen"
 	"github.com/risingwavelabs/events-api/pkg/config"
 	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
+	"github.com/risingwavelabs/events-api/pkg/ratelimit"
+	"github.com/risingwavelabs/events-api/pkg/rw"
 	"go.uber.org/zap"
 )
 
@@ -23,26 +25,20 @@ type App struct {
 }
 
 func ErrorHandler(c *fiber.Ctx, err error) error {
-	var code = fiber.StatusInternalServerError
+	rid, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	status, body := errorBody(err, rid)
 
-	var e *fiber.Error
-	if errors.As(err, &e) {
-		code = e.Code
-	}
-
-	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
-
-	rid := c.Locals(requestid.ConfigDefault.ContextKey)
-
-	if code == fiber.StatusInternalServerError {
+	if status == fiber.StatusInternalServerError {
 		log.Info(fmt.Sprintf("unexpected error, request-id: %v, err: %v", rid, err), zap.Error(err), zap.String("path", c.Path()))
-		return c.Status(code).SendString(fmt.Sprintf("unexpected error, request-id: %v", rid))
+	}
+	if status == fiber.StatusServiceUnavailable && c.GetRespHeader(fiber.HeaderRetryAfter) == "" {
+		c.Set(fiber.HeaderRetryAfter, "1")
 	}
 
-	return c.Status(code).SendString(err.Error())
+	return c.Status(status).JSON(body)
 }
 
-func NewApp(cfg *config.Config, gctx *gctx.GlobalContext, _log *zap.Logger, si apigen.ServerInterface) *App {
+func NewApp(cfg *config.Config, gctx *gctx.GlobalContext, _log *zap.Logger, si apigen.ServerInterface, ws *WebSocketHandler, limiter *ratelimit.Limiter, es *rw.EventService, idem *idempotency.Store) (*App, error) {
 	log := _log.Named("app")
 
 	app := fiber.New(fiber.Config{
@@ -57,6 +53,21 @@ func NewApp(cfg *config.Config, gctx *gctx.GlobalContext, _log *zap.Logger, si a
 
 	app.Use(requestid.New())
 
+	app.Use(NewAPIKeyMiddleware(cfg.APIKeys))
+
+	clientIP, err := NewClientIPMiddleware(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	app.Use(clientIP)
+
+	// replays do not count against the rate limits, the events are not ingested again
+	app.Use(NewIdempotencyMiddleware(idem))
+
+	app.Use(NewRateLimitMiddleware(limiter, es))
+
+	app.Get("/v1/ws", ws.Upgrade, ws.Handler())
+
 	apigen.RegisterHandlersWithOptions(app, si, apigen.FiberServerOptions{
 		BaseURL: "/v1",
 	})
@@ -88,7 +99,7 @@ func NewApp(cfg *config.Config, gctx *gctx.GlobalContext, _log *zap.Logger, si a
 		port: port,
 		gctx: gctx,
 		host: host,
-	}
+	}, nil
 }
 
 func (a *App) Listen() error {
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+)
+
+func (h *Handler) ListTables(c *fiber.Ctx) error {
+	relations := h.watcher.Relations()
+	ret := make([]apigen.Table, len(relations))
+	for i, r := range relations {
+		ret[i] = h.table(r)
+	}
+	return c.JSON(ret)
+}
+
+func (h *Handler) GetTable(c *fiber.Ctx, name string) error {
+	r, ok := h.watcher.Relation(name)
+	if !ok {
+		return errors.Wrapf(rw.ErrRelationNotFound, "no table %s", h.searchPath.QualifiedName(name))
+	}
+	return c.JSON(h.table(r))
+}
+
+func (h *Handler) GetTableSchema(c *fiber.Ctx, name string) error {
+	r, ok := h.watcher.Relation(name)
+	if !ok {
+		return errors.Wrapf(rw.ErrRelationNotFound, "no table %s", h.searchPath.QualifiedName(name))
+	}
+	return c.JSON(tableColumns(r.Columns))
+}
+
+func (h *Handler) table(r rw.Relation) apigen.Table {
+	return apigen.Table{
+		Id:         r.ID,
+		Schema:     r.Schema,
+		Name:       r.Name,
+		Type:       string(r.Type),
+		Definition: r.Definition,
+		Ingestible: h.es.Ingestible(r.QualifiedName()),
+		Columns:    tableColumns(r.Columns),
+	}
+}
+
+func tableColumns(cols []rw.Column) []apigen.TableColumn {
+	ret := make([]apigen.TableColumn, len(cols))
+	for i, c := range cols {
+		ret[i] = apigen.TableColumn{
+			Name:         c.Name,
+			Type:         c.Type,
+			IsPrimaryKey: c.IsPrimaryKey,
+			IsHidden:     c.IsHidden,
+			Ingestible:   rw.Ingestible(c),
+		}
+	}
+	return ret
+}
+
+func (h *Handler) GetTableIngestion(c *fiber.Ctx, name string) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	status, err := h.es.Ingestion(name)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tableIngestion(status))
+}
+
+func (h *Handler) SetTableIngestion(c *fiber.Ctx, name string) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request: "+err.Error())
+	}
+	if req.Enabled == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "enabled is required")
+	}
+	status, err := h.es.SetIngestion(name, *req.Enabled)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tableIngestion(status))
+}
+
+func tableIngestion(s rw.IngestionStatus) apigen.TableIngestion {
+	return apigen.TableIngestion{
+		Table:    s.Table,
+		Enabled:  s.Enabled,
+		Excluded: s.Excluded,
+	}
+}
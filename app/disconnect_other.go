@@ -0,0 +1,10 @@
+//go:build !unix
+
+package app
+
+import "net"
+
+// connClosed cannot detect closed connections on this platform, queries run until they finish or time out.
+func connClosed(conn net.Conn) bool {
+	return false
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/savedquery"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+)
+
+// Error codes of the error response body.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeInvalidEvent      = "invalid_event"
+	CodeQueryFailed       = "query_failed"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeRelationNotFound  = "relation_not_found"
+	CodeIngestionDisabled = "ingestion_disabled"
+	CodeNotAcceptable     = "not_acceptable"
+	CodeConflict          = "conflict"
+	CodeKeyReused         = "idempotency_key_reused"
+	CodePayloadTooLarge   = "payload_too_large"
+	CodeRateLimited       = "rate_limited"
+	CodeUnavailable       = "unavailable"
+	CodeTimeout           = "timeout"
+	CodeCanceled          = "canceled"
+	CodeInternal          = "internal"
+)
+
+var codeByStatus = map[int]string{
+	fiber.StatusBadRequest:            CodeBadRequest,
+	fiber.StatusUnauthorized:          CodeUnauthorized,
+	fiber.StatusForbidden:             CodeForbidden,
+	fiber.StatusNotFound:              CodeNotFound,
+	fiber.StatusNotAcceptable:         CodeNotAcceptable,
+	fiber.StatusConflict:              CodeConflict,
+	fiber.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	fiber.StatusTooManyRequests:       CodeRateLimited,
+	fiber.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// errorBody returns the status and the response body of an error. The message of unexpected errors is not exposed,
+// they are found in the logs by request id.
+func errorBody(err error, requestID string) (int, apigen.Error) {
+	status, code := classifyError(err)
+	body := apigen.Error{
+		Code:    code,
+		Message: err.Error(),
+	}
+	if status == fiber.StatusInternalServerError {
+		body.Message = "unexpected error"
+	}
+	if requestID != "" {
+		body.RequestId = &requestID
+	}
+	if state := rw.SQLState(err); state != "" {
+		body.Sqlstate = &state
+	}
+	return status, body
+}
+
+// classifyError returns the HTTP status and error code of an error returned by a handler.
+func classifyError(err error) (int, string) {
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		if code, ok := codeByStatus[e.Code]; ok {
+			return e.Code, code
+		}
+		if e.Code < fiber.StatusInternalServerError {
+			return e.Code, CodeBadRequest
+		}
+		return e.Code, CodeInternal
+	}
+
+	var v *statement.Violation
+	switch {
+	case errors.As(err, &v):
+		return fiber.StatusForbidden, CodeForbidden
+	case errors.Is(err, rw.ErrRelationNotFound):
+		return fiber.StatusNotFound, CodeRelationNotFound
+	case errors.Is(err, rw.ErrIngestionDisabled):
+		return fiber.StatusForbidden, CodeIngestionDisabled
+	case errors.Is(err, rw.ErrInvalidEvent):
+		return fiber.StatusBadRequest, CodeInvalidEvent
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return fiber.StatusUnprocessableEntity, CodeKeyReused
+	case errors.Is(err, rw.ErrQueryTimeout),
+		// e.g. a flush of ingested events that RisingWave did not complete in time
+		errors.Is(err, context.DeadlineExceeded):
+		return fiber.StatusGatewayTimeout, CodeTimeout
+	case errors.Is(err, rw.ErrQueryCanceled):
+		return fiber.StatusBadRequest, CodeCanceled
+	case errors.Is(err, rw.ErrNotFound):
+		return fiber.StatusNotFound, CodeNotFound
+	case errors.Is(err, rw.ErrNotBackgroundDDL),
+		errors.Is(err, rw.ErrReadOnlyTransaction),
+		errors.Is(err, savedquery.ErrInvalidParam),
+		errors.Is(err, idempotency.ErrInvalidKey):
+		return fiber.StatusBadRequest, CodeBadRequest
+	case errors.Is(err, rw.ErrUnavailable),
+		errors.Is(err, rw.ErrTooManySubscriptions),
+		errors.Is(err, rw.ErrInsertBackpressure),
+		errors.Is(err, rw.ErrBulkInsertClosed):
+		return fiber.StatusServiceUnavailable, CodeUnavailable
+	case errors.Is(err, rw.ErrQueryFailed):
+		return classifySQLState(rw.SQLState(err))
+	}
+	return fiber.StatusInternalServerError, CodeInternal
+}
+
+// classifySQLState maps the SQLSTATE of a failed statement to a status. Most errors of RisingWave are XX000, which
+// is a client error as far as the API is concerned.
+func classifySQLState(state string) (int, string) {
+	switch {
+	case state == "42P01": // undefined_table
+		return fiber.StatusNotFound, CodeRelationNotFound
+	case strings.HasPrefix(state, "23"), // integrity_constraint_violation
+		state == "42P07", // duplicate_table
+		state == "42710": // duplicate_object
+		return fiber.StatusConflict, CodeConflict
+	case strings.HasPrefix(state, "08"), // connection_exception
+		strings.HasPrefix(state, "53"),  // insufficient_resources
+		strings.HasPrefix(state, "57P"): // operator_intervention, e.g. admin_shutdown
+		return fiber.StatusServiceUnavailable, CodeUnavailable
+	}
+	return fiber.StatusBadRequest, CodeQueryFailed
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBody(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fiber.NewError(fiber.StatusTooManyRequests, "slow down"), fiber.StatusTooManyRequests, CodeRateLimited},
+		{errors.Wrap(errors.Wrap(rw.ErrRelationNotFound, "no table public.t"), "failed to ingest"), fiber.StatusNotFound, CodeRelationNotFound},
+		{errors.Wrap(rw.ErrIngestionDisabled, "ingestion into public.t is disabled"), fiber.StatusForbidden, CodeIngestionDisabled},
+		{errors.Wrap(rw.ErrInvalidEvent, "bad json"), fiber.StatusBadRequest, CodeInvalidEvent},
+		{idempotency.ErrKeyReused, fiber.StatusUnprocessableEntity, CodeKeyReused},
+		{&statement.Violation{Kind: "CREATE_SUBSCRIPTION", Reason: "only read-only statements are allowed"}, fiber.StatusForbidden, CodeForbidden},
+		{errors.Wrap(fmt.Errorf("timeout: %w", context.DeadlineExceeded), "failed to ingest"), fiber.StatusGatewayTimeout, CodeTimeout},
+		{rw.ErrInsertBackpressure, fiber.StatusServiceUnavailable, CodeUnavailable},
+		{errors.New("boom"), fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tc := range testCases {
+		status, body := errorBody(tc.err, "rid")
+		require.Equal(t, tc.status, status, tc.err.Error())
+		require.Equal(t, tc.code, body.Code, tc.err.Error())
+		require.Equal(t, "rid", *body.RequestId)
+		require.Nil(t, body.Sqlstate)
+	}
+
+	_, body := errorBody(errors.New("secret"), "")
+	require.Equal(t, "unexpected error", body.Message)
+	require.Nil(t, body.RequestId)
+}
+
+func TestClassifySQLState(t *testing.T) {
+	for state, status := range map[string]int{
+		"XX000": fiber.StatusBadRequest,
+		"42P01": fiber.StatusNotFound,
+		"23505": fiber.StatusConflict,
+		"53300": fiber.StatusServiceUnavailable,
+	} {
+		s, _ := classifySQLState(state)
+		require.Equal(t, status, s, state)
+	}
+}
@@ -1,38 +1,507 @@
 package app
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/pkg/errors"
 	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/querycache"
 	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/savedquery"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderCache tells whether a query result was served from the cache, HIT or MISS.
+	HeaderCache = "X-Cache"
+
+	// HeaderEventOp is the operation of the ingested events that do not set one, see rw.EventOpField.
+	HeaderEventOp = "X-Events-Op"
+
+	// maxBatchStatements is the maximum number of statements of a batch.
+	maxBatchStatements = 1000
+
+	// catalogUpdateTimeout bounds the catalog update after DDL.
+	catalogUpdateTimeout = 5 * time.Second
+
+	// streamFlushRows is the number of rows after which a streamed query result is flushed to the client.
+	streamFlushRows = 1000
 )
 
 type Handler struct {
-	rw *rw.RisingWave
-	es *rw.EventService
+	rw         *rw.RisingWave
+	es         *rw.EventService
+	watcher    *rw.Watcher
+	policy     *statement.Policy
+	subscriber *rw.Subscriber
+	saved      *savedquery.Registry
+	cache      *querycache.Cache
+	timeouts   queryTimeouts
+	queries    *queryRegistry
+	adminKeys  map[string]bool
+	searchPath rw.SearchPath
+	log        *zap.Logger
 }
 
-func NewHandler(rw *rw.RisingWave, es *rw.EventService) apigen.ServerInterface {
-	return &Handler{
-		rw: rw,
-		es: es,
+func NewHandler(cfg *config.Config, searchPath rw.SearchPath, rw *rw.RisingWave, es *rw.EventService, watcher *rw.Watcher, policy *statement.Policy, subscriber *rw.Subscriber, saved *savedquery.Registry, cache *querycache.Cache, log *zap.Logger) (apigen.ServerInterface, error) {
+	timeouts, err := newQueryTimeouts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	adminKeys := make(map[string]bool, len(cfg.AdminKeys))
+	for _, id := range cfg.AdminKeys {
+		if id != "" {
+			adminKeys[id] = true
+		}
 	}
+	return &Handler{
+		rw:         rw,
+		es:         es,
+		watcher:    watcher,
+		policy:     policy,
+		subscriber: subscriber,
+		saved:      saved,
+		cache:      cache,
+		timeouts:   timeouts,
+		queries:    newQueryRegistry(),
+		adminKeys:  adminKeys,
+		searchPath: searchPath,
+		log:        log.Named("handler"),
+	}, nil
 }
 
 func (h *Handler) IngestEvent(c *fiber.Ctx, params apigen.IngestEventParams) error {
-	if err := h.es.IngestEvent(c.Context(), params.Name, c.Body()); err != nil {
+	return h.ingest(c, params.Name)
+}
+
+func (h *Handler) IngestTableEvent(c *fiber.Ctx, name string) error {
+	return h.ingest(c, name)
+}
+
+func (h *Handler) ingest(c *fiber.Ctx, name string) error {
+	op, err := rw.ParseEventOp(c.Get(HeaderEventOp))
+	if err != nil {
+		return err
+	}
+	if err := h.es.IngestEvent(c.Context(), name, op, c.Body(), requestMeta(c)); err != nil {
 		return err
 	}
+	h.cache.Invalidate(name)
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// requestMeta returns what the enriched columns of the ingested events are filled with.
+func requestMeta(c *fiber.Ctx) *rw.RequestMeta {
+	rid, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	return &rw.RequestMeta{
+		ReceivedAt: time.Now(),
+		RequestID:  rid,
+		ClientIP:   ClientIP(c),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+		APIKeyID:   APIKeyID(c),
+	}
+}
+
 func (h *Handler) HealthCheck(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
-func (h *Handler) ExecuteSQL(c *fiber.Ctx) error {
-	res, err := h.rw.QueryDatabase(c.Context(), string(c.Body()))
+func (h *Handler) ExecuteSQL(c *fiber.Ctx, opts apigen.ExecuteSQLParams) error {
+	sql, params, err := parseQueryRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkPolicy(c, sql); err != nil {
+		return err
+	}
+
+	background := opts.Background != nil && *opts.Background
+	if background && len(params) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "background DDL does not take parameters")
+	}
+
+	format, err := acceptedFormat(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := h.queryContext(c, sql, opts.Timeout)
+	if err != nil {
+		return err
+	}
+	// DDL streamed with another format is picked up by the periodic catalog update
+	if background || format == fiber.MIMEApplicationJSON {
+		defer h.afterWrite(c, sql)
+	}
+
+	if background {
+		defer done()
+		job, err := h.rw.ExecuteBackgroundDDL(ctx, sql)
+		if err != nil {
+			return queryError(ctx, err)
+		}
+		c.Location(fmt.Sprintf("/v1/ddl/%d", job.Id))
+		return c.Status(fiber.StatusAccepted).JSON(job)
+	}
+
+	return h.respondQuery(c, ctx, done, format, sql, params)
+}
+
+func (h *Handler) ExecuteSQLBatch(c *fiber.Ctx, opts apigen.ExecuteSQLBatchParams) error {
+	var req apigen.BatchRequest
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid batch request: "+err.Error())
+	}
+	if len(req.Statements) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "the batch has no statements")
+	}
+	if len(req.Statements) > maxBatchStatements {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("a batch has at most %d statements", maxBatchStatements))
+	}
+
+	stmts := make([]rw.BatchStatement, len(req.Statements))
+	scripts := make([]string, len(req.Statements))
+	for i, s := range req.Statements {
+		if err := h.checkPolicy(c, s.Sql); err != nil {
+			var e *fiber.Error
+			if errors.As(err, &e) {
+				return fiber.NewError(e.Code, fmt.Sprintf("statement %d: %s", i+1, e.Message))
+			}
+			return err
+		}
+		stmts[i].SQL = s.Sql
+		scripts[i] = s.Sql
+		if s.Params != nil {
+			params, err := rw.ConvertParams(*s.Params)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("statement %d: %s", i+1, err.Error()))
+			}
+			stmts[i].Params = params
+		}
+	}
+
+	ctx, done, err := h.queryContext(c, strings.Join(scripts, ";\n"), opts.Timeout)
+	if err != nil {
+		return err
+	}
+	defer done()
+	defer h.afterWrite(c, scripts...)
+
+	results, err := h.rw.ExecuteBatch(ctx, stmts, req.Transaction != nil && *req.Transaction)
+	for i := range results {
+		h.watcher.AnnotateColumns(stmts[i].SQL, results[i].Columns)
+	}
+	rid, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	status, res, err := h.batchResponse(ctx, results, err, rid)
+	if err != nil {
+		return err
+	}
+	return c.Status(status).JSON(res)
+}
+
+// batchResponse returns the response of a batch. A batch with a failed statement gets the status of its error and
+// still has the results of the statements before it, other errors are returned as is.
+func (h *Handler) batchResponse(ctx context.Context, results []apigen.QueryResponse, err error, rid string) (int, apigen.BatchResponse, error) {
+	if results == nil {
+		results = []apigen.QueryResponse{}
+	}
+	res := apigen.BatchResponse{Results: results}
+	if err == nil {
+		return fiber.StatusOK, res, nil
+	}
+	var batchErr *rw.BatchError
+	if !errors.As(err, &batchErr) {
+		return 0, res, queryError(ctx, err)
+	}
+	status, body := errorBody(queryError(ctx, batchErr.Err), rid)
+	if status == fiber.StatusInternalServerError {
+		h.log.Error("unexpected error in batch", zap.String("request_id", rid), zap.Int("index", batchErr.Index), zap.Error(batchErr.Err))
+	}
+	res.FailedIndex = &batchErr.Index
+	res.Error = &body
+	return status, res, nil
+}
+
+func (h *Handler) RunSavedQuery(c *fiber.Ctx, name string) error {
+	q, ok := h.saved.Get(name)
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "no saved query "+name)
+	}
+	if !q.Allows(APIKeyID(c)) {
+		return fiber.NewError(fiber.StatusForbidden, "saved query "+name+" is not allowed for this api key")
+	}
+
+	args := make(map[string][]string)
+	c.Context().QueryArgs().VisitAll(func(k, v []byte) {
+		args[string(k)] = append(args[string(k)], string(v))
+	})
+	params, err := q.Bind(args)
+	if err != nil {
+		return err
+	}
+
+	format, err := acceptedFormat(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := h.queryContext(c, q.SQL, nil)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case q.MaxAge <= 0:
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+	case q.Public():
+		c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", q.MaxAge))
+	default:
+		c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", q.MaxAge))
+	}
+	return h.respondQuery(c, ctx, done, format, q.SQL, params)
+}
+
+// afterWrite keeps the caches of the server in line with the scripts that have run. The catalog is updated right
+// after DDL, so that new tables can be ingested into as soon as the response is received, and the cached results of
+// the relations written to are dropped.
+func (h *Handler) afterWrite(c *fiber.Ctx, scripts ...string) {
+	for _, sql := range scripts {
+		if statement.HasDDL(sql) {
+			ctx, cancel := context.WithTimeout(c.Context(), catalogUpdateTimeout)
+			defer cancel()
+			if err := h.watcher.UpdateCache(ctx); err != nil {
+				h.log.Warn("failed to update the catalog after DDL", zap.Error(err))
+			}
+			break
+		}
+	}
+
+	if h.cache.Enabled() {
+		for _, sql := range scripts {
+			for _, r := range h.cache.Writes(sql) {
+				h.cache.Invalidate(r)
+			}
+		}
+	}
+}
+
+// acceptedFormat returns the format of the query result asked for by the Accept header.
+func acceptedFormat(c *fiber.Ctx) (string, error) {
+	format := c.Accepts(fiber.MIMEApplicationJSON, rw.MIMENDJSON, rw.MIMECSV, rw.MIMEArrowStream)
+	if format == "" {
+		return "", fiber.NewError(fiber.StatusNotAcceptable, "supported formats are application/json, "+rw.MIMENDJSON+", "+rw.MIMECSV+" and "+rw.MIMEArrowStream)
+	}
+	return format, nil
+}
+
+// respondQuery runs the query and writes its result in the given format, done is called once the query is finished.
+func (h *Handler) respondQuery(c *fiber.Ctx, ctx context.Context, done func(), format string, sql string, params []any) error {
+	if format != fiber.MIMEApplicationJSON {
+		return h.streamQuery(c, ctx, done, format, sql, params)
+	}
+
+	defer done()
+
+	var (
+		key       string
+		relations []string
+		cacheable bool
+	)
+	if h.cache.Enabled() {
+		key, relations, cacheable = h.cache.Key(sql, params)
+	}
+	// Cache-Control: no-cache skips the lookup but refreshes the cached result
+	if cacheable && !strings.Contains(c.Get(fiber.HeaderCacheControl), "no-cache") {
+		if e, ok := h.cache.Get(key); ok {
+			c.Set(HeaderCache, "HIT")
+			return sendCached(c, e)
+		}
+	}
+
+	res, err := h.rw.QueryDatabase(ctx, sql, params...)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	h.watcher.AnnotateColumns(sql, res.Columns)
+	if !cacheable {
+		return c.JSON(res)
+	}
+
+	body, err := json.Marshal(res)
 	if err != nil {
+		return errors.Wrap(err, "failed to marshal query result")
+	}
+	c.Set(HeaderCache, "MISS")
+	return sendCached(c, h.cache.Put(key, relations, body))
+}
+
+// sendCached sends a cacheable query result, or 304 Not Modified if the client already has it.
+func sendCached(c *fiber.Ctx, e *querycache.Entry) error {
+	c.Set(fiber.HeaderETag, e.ETag)
+	if c.Fresh() {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(e.Body)
+}
+
+func (h *Handler) checkPolicy(c *fiber.Ctx, sql string) error {
+	if err := h.policy.Check(APIKeyID(c), sql); err != nil {
+		var v *statement.Violation
+		if errors.As(err, &v) {
+			return fiber.NewError(fiber.StatusForbidden, v.Error())
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// parseQueryRequest accepts either the raw SQL as text/plain or an application/json QueryRequest with parameters.
+func parseQueryRequest(c *fiber.Ctx) (string, []any, error) {
+	if !c.Is("json") {
+		return string(c.Body()), nil, nil
+	}
+
+	var req apigen.QueryRequest
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		return "", nil, fiber.NewError(fiber.StatusBadRequest, "invalid query request: "+err.Error())
+	}
+	if req.Params == nil {
+		return req.Sql, nil, nil
+	}
+
+	params, err := rw.ConvertParams(*req.Params)
+	if err != nil {
+		return "", nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return req.Sql, params, nil
+}
+
+// streamQuery writes the rows to the response as they are scanned, using chunked transfer encoding. The query
+// context lives until the response is written.
+func (h *Handler) streamQuery(c *fiber.Ctx, ctx context.Context, done func(), format string, sql string, params []any) error {
+	stream, err := h.rw.StreamQuery(ctx, sql, params...)
+	if err != nil {
+		done()
+		return queryError(ctx, err)
+	}
+
+	c.Set(fiber.HeaderContentType, format)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer done()
+		defer stream.Close()
+
+		enc, err := rw.NewRowEncoder(format, w)
+		if err != nil {
+			h.log.Error("failed to create row encoder", zap.Error(err))
+			return
+		}
+		if err := rw.CopyRows(stream, enc, streamFlushRows, w.Flush); err != nil {
+			h.log.Warn("query stream interrupted", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (h *Handler) RefreshCatalog(c *fiber.Ctx) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	if err := h.watcher.UpdateCache(c.Context()); err != nil {
+		return errors.Wrap(rw.ErrUnavailable, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// requireAdmin rejects requests whose API key is not an admin key.
+func (h *Handler) requireAdmin(c *fiber.Ctx) error {
+	if !h.adminKeys[APIKeyID(c)] {
+		return fiber.NewError(fiber.StatusForbidden, "an admin api key is required")
+	}
+	return nil
+}
+
+func (h *Handler) GetDDLJob(c *fiber.Ctx, id int64) error {
+	job, err := h.rw.DDLProgress(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(job)
+}
+
+func (h *Handler) Subscribe(c *fiber.Ctx, name string, params apigen.SubscribeParams) error {
+	since := params.Since
+	if id := c.Get("Last-Event-ID"); id != "" {
+		ts, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid Last-Event-ID: "+id)
+		}
+		since = &ts
+	}
+
+	// the request context is cancelled when the server shuts down, which ends the stream
+	ctx, cancel := context.WithCancel(c.Context())
+
+	sub, err := h.subscriber.Open(ctx, APIKeyID(c), name, since)
+	if err != nil {
+		cancel()
 		return err
 	}
-	return c.JSON(res)
+
+	rid, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer sub.Close()
+
+		for {
+			events, err := sub.Fetch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					h.log.Warn("subscription interrupted", zap.String("relation", name), zap.Error(err))
+					_, body := errorBody(err, rid)
+					data, _ := json.Marshal(body)
+					fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+					_ = w.Flush()
+				}
+				return
+			}
+
+			if len(events) == 0 {
+				_, _ = w.WriteString(": ping\n\n")
+			}
+			for _, e := range events {
+				data, err := json.Marshal(e)
+				if err != nil {
+					h.log.Error("failed to marshal change event", zap.Error(err))
+					return
+				}
+				fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.Timestamp, data)
+			}
+
+			// a failed flush means the client has gone away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
 }
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBatchResponse(t *testing.T) {
+	h := &Handler{log: zap.NewNop()}
+	ctx := context.Background()
+	results := []apigen.QueryResponse{{RowsAffected: 1}}
+
+	status, res, err := h.batchResponse(ctx, results, nil, "rid")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, results, res.Results)
+	require.Nil(t, res.FailedIndex)
+
+	// a failed statement gets the status of its error along with the results before it
+	status, res, err = h.batchResponse(ctx, results, &rw.BatchError{Index: 1, Err: errors.Wrap(rw.ErrRelationNotFound, "no table t")}, "rid")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, status)
+	require.Equal(t, results, res.Results)
+	require.Equal(t, 1, *res.FailedIndex)
+	require.Equal(t, CodeRelationNotFound, res.Error.Code)
+	require.Equal(t, "rid", *res.Error.RequestId)
+
+	// a transaction rejected up front has no results
+	status, res, err = h.batchResponse(ctx, nil, &rw.BatchError{Index: 0, Err: rw.ErrReadOnlyTransaction}, "rid")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, status)
+	require.NotNil(t, res.Results)
+	require.Empty(t, res.Results)
+	require.Equal(t, 0, *res.FailedIndex)
+
+	// the timeout of the batch wins over the error it caused
+	tctx, cancel := context.WithCancelCause(ctx)
+	cancel(rw.ErrQueryTimeout)
+	status, res, err = h.batchResponse(tctx, results, &rw.BatchError{Index: 1, Err: context.Canceled}, "rid")
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusGatewayTimeout, status)
+	require.Equal(t, CodeTimeout, res.Error.Code)
+
+	// errors outside of the statements fail the whole request
+	_, _, err = h.batchResponse(ctx, nil, rw.ErrUnavailable, "rid")
+	require.ErrorIs(t, err, rw.ErrUnavailable)
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
+	"github.com/risingwavelabs/events-api/pkg/ratelimit"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	// HeaderIdempotencyKey identifies an ingest request across retries, see NewIdempotencyMiddleware.
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed is set on responses replayed from an earlier request with the same idempotency key.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	localsAPIKeyID = "apiKeyID"
+	localsClientIP = "clientIP"
+)
+
+// APIKeyID returns the id of the API key the request was authenticated with, or an empty string for anonymous requests.
+func APIKeyID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(localsAPIKeyID).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ClientIP returns the IP of the client of the request, see NewClientIPMiddleware.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(localsClientIP).(string); ok {
+		return ip
+	}
+	return c.IP()
+}
+
+// NewAPIKeyMiddleware resolves the API key of the request to its id. Keys are sent in X-API-Key or as a bearer
+// token. Requests without a key are anonymous, and so are requests with an unknown bearer token, which may be meant
+// for a proxy in front of the server. An unknown X-API-Key is rejected, unless no key is configured.
+func NewAPIKeyMiddleware(keys []config.APIKey) fiber.Handler {
+	idByKey := make(map[string]string, len(keys))
+	for _, k := range keys {
+		idByKey[k.Key] = k.ID
+	}
+
+	return func(c *fiber.Ctx) error {
+		if len(idByKey) == 0 {
+			return c.Next()
+		}
+		if key := c.Get(HeaderAPIKey); key != "" {
+			id, ok := idByKey[key]
+			if !ok {
+				return fiber.NewError(fiber.StatusUnauthorized, "invalid api key")
+			}
+			c.Locals(localsAPIKeyID, id)
+			return c.Next()
+		}
+		if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+			if id, ok := idByKey[strings.TrimPrefix(auth, "Bearer ")]; ok {
+				c.Locals(localsAPIKeyID, id)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// NewClientIPMiddleware resolves the IP of the client of requests from trusted proxies. X-Forwarded-For is read from
+// the right and the first address that is not a trusted proxy is the client, so that clients cannot choose their IP
+// by sending the header themselves.
+func NewClientIPMiddleware(proxies []string) (fiber.Handler, error) {
+	trusted := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(p)
+			if aerr != nil {
+				return nil, errors.Wrapf(err, "invalid trusted proxy %q", p)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	return func(c *fiber.Ctx) error {
+		if len(trusted) > 0 {
+			c.Locals(localsClientIP, clientIP(c.IP(), c.Request().Header.PeekAll(fiber.HeaderXForwardedFor), trusted))
+		}
+		return c.Next()
+	}, nil
+}
+
+func clientIP(remote string, forwarded [][]byte, trusted []netip.Prefix) string {
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, p := range trusted {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var hops []string
+	for _, header := range forwarded {
+		for hop := range strings.SplitSeq(string(header), ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	ip := remote
+	for i := len(hops) - 1; i >= 0 && isTrusted(ip); i-- {
+		if _, err := netip.ParseAddr(hops[i]); err != nil {
+			break
+		}
+		ip = hops[i]
+	}
+	return ip
+}
+
+// NewRateLimitMiddleware enforces the rate limits and daily quotas of the limiter. The events of a request are
+// the non-empty lines of the body of /events requests.
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter, es *rw.EventService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !limiter.Enabled() || c.Path() == "/v1/healthz" {
+			return c.Next()
+		}
+
+		req := ratelimit.Request{
+			APIKey: APIKeyID(c),
+			IP:     ClientIP(c),
+			Bytes:  int64(len(c.Body())),
+		}
+		if table, ok := ingestTable(c); ok {
+			if table != "" {
+				req.Table = es.QualifiedName(table)
+			}
+			req.Events = countLines(c.Body())
+		}
+
+		d := limiter.Allow(req)
+		for _, s := range d.Rates {
+			c.Set("X-RateLimit-Limit-"+headerDim(s.Dimension), formatTokens(s.Limit))
+			c.Set("X-RateLimit-Remaining-"+headerDim(s.Dimension), formatTokens(s.Remaining))
+		}
+		for _, s := range d.Quotas {
+			c.Set("X-Quota-Limit-"+headerDim(s.Dimension), formatTokens(s.Limit))
+			c.Set("X-Quota-Remaining-"+headerDim(s.Dimension), formatTokens(s.Remaining))
+		}
+
+		if !d.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(d.RetryAfter.Seconds())), 10))
+			return fiber.NewError(fiber.StatusTooManyRequests, d.Reason)
+		}
+		return c.Next()
+	}
+}
+
+// NewIdempotencyMiddleware answers ingest requests with an Idempotency-Key header that were already run with the
+// response of the first run, so that retries do not ingest the events twice. Keys are scoped by API key and bound to
+// the request they were first sent with. Server errors and rate limited requests are not remembered, they can be
+// retried with the same key.
+func NewIdempotencyMiddleware(store *idempotency.Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" || c.Method() != fiber.MethodPost {
+			return c.Next()
+		}
+		if _, ok := ingestTable(c); !ok {
+			return c.Next()
+		}
+
+		result, done, err := store.Begin(c.UserContext(), APIKeyID(c)+"\x00"+key, requestFingerprint(c))
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			c.Set(HeaderIdempotentReplayed, "true")
+			c.Set(fiber.HeaderContentType, result.ContentType)
+			return c.Status(result.Status).Send(result.Body)
+		}
+
+		var stored *idempotency.Result
+		defer func() { done(stored) }()
+
+		// the error is rendered here rather than by the app, so that the response can be remembered
+		if err := c.Next(); err != nil {
+			if err := ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		if status := c.Response().StatusCode(); status < fiber.StatusInternalServerError && status != fiber.StatusTooManyRequests {
+			stored = &idempotency.Result{
+				Status:      status,
+				ContentType: string(c.Response().Header.ContentType()),
+				Body:        bytes.Clone(c.Response().Body()),
+			}
+		}
+		return nil
+	}
+}
+
+// requestFingerprint identifies the content of a request, a key sent again with a different request is an error.
+func requestFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(c.Path()), c.Request().URI().QueryString(), []byte(c.Get(HeaderEventOp)), c.Body()} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ingestTable returns the table of an ingest request, from the name parameter of /events or the path of
+// /events/{name}. Middlewares run before routing, so the path is parsed here.
+func ingestTable(c *fiber.Ctx) (string, bool) {
+	if c.Path() == "/v1/events" {
+		return c.Query("name"), true
+	}
+	if name, ok := strings.CutPrefix(c.Path(), "/v1/events/"); ok && c.Method() == fiber.MethodPost {
+		name, err := url.PathUnescape(name)
+		if err != nil {
+			return "", true
+		}
+		return name, true
+	}
+	return "", false
+}
+
+func headerDim(dim string) string {
+	return strings.ToUpper(dim[:1]) + dim[1:]
+}
+
+func formatTokens(v float64) string {
+	return strconv.FormatInt(int64(math.Floor(v)), 10)
+}
+
+func countLines(body []byte) int64 {
+	var n int64
+	for line := range bytes.SplitSeq(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			n++
+		}
+	}
+	return n
+}
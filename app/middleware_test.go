@@ -0,0 +1,77 @@
+package app
+
+import (
+	"io"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	xff := func(v ...string) [][]byte {
+		ret := make([][]byte, len(v))
+		for i, s := range v {
+			ret[i] = []byte(s)
+		}
+		return ret
+	}
+
+	require.Equal(t, "198.51.100.1", clientIP("198.51.100.1", xff("203.0.113.7"), trusted), "untrusted peer")
+	require.Equal(t, "203.0.113.7", clientIP("10.0.0.1", xff("203.0.113.7"), trusted))
+	require.Equal(t, "203.0.113.7", clientIP("10.0.0.1", xff("1.2.3.4, 203.0.113.7", "10.0.0.2"), trusted), "spoofed hops are ignored")
+	require.Equal(t, "10.0.0.2", clientIP("10.0.0.1", xff("garbage, 10.0.0.2"), trusted))
+	require.Equal(t, "10.0.0.1", clientIP("10.0.0.1", nil, trusted))
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	newApp := func(keys []config.APIKey) *fiber.App {
+		app := fiber.New()
+		app.Use(NewAPIKeyMiddleware(keys))
+		app.Get("/", func(c *fiber.Ctx) error {
+			return c.SendString(APIKeyID(c))
+		})
+		return app
+	}
+	do := func(app *fiber.App, header, value string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	app := newApp([]config.APIKey{{ID: "frontend", Key: "secret"}})
+	testCases := []struct {
+		header, value string
+		status        int
+		id            string
+	}{
+		{"", "", fiber.StatusOK, ""},
+		{HeaderAPIKey, "secret", fiber.StatusOK, "frontend"},
+		{HeaderAPIKey, "wrong", fiber.StatusUnauthorized, ""},
+		{fiber.HeaderAuthorization, "Bearer secret", fiber.StatusOK, "frontend"},
+		{fiber.HeaderAuthorization, "Bearer proxy-token", fiber.StatusOK, ""},
+		{fiber.HeaderAuthorization, "Basic dXNlcjpwYXNz", fiber.StatusOK, ""},
+	}
+	for _, tc := range testCases {
+		status, body := do(app, tc.header, tc.value)
+		require.Equal(t, tc.status, status, tc.value)
+		if status == fiber.StatusOK {
+			require.Equal(t, tc.id, body, tc.value)
+		}
+	}
+
+	// without configured keys, every request is anonymous
+	status, body := do(newApp(nil), HeaderAPIKey, "anything")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Empty(t, body)
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+)
+
+const (
+	HeaderStatementTimeout = "X-Statement-Timeout"
+
+	// HeaderQueryID is the id of the query of a response, it is used to cancel the query while it runs.
+	HeaderQueryID = "X-Query-ID"
+
+	disconnectPollInterval = 500 * time.Millisecond
+)
+
+// errClientGone is the cause of the cancellation of the queries of clients that have disconnected.
+var errClientGone = errors.New("client disconnected")
+
+// queryTimeouts are the default and maximum statement timeouts of the SQL endpoint, zero means no timeout.
+type queryTimeouts struct {
+	def time.Duration
+	max time.Duration
+}
+
+func newQueryTimeouts(cfg *config.Config) (queryTimeouts, error) {
+	var (
+		t   queryTimeouts
+		err error
+	)
+	if cfg.SQL.Timeout != "" {
+		if t.def, err = time.ParseDuration(cfg.SQL.Timeout); err != nil {
+			return t, errors.Wrap(err, "invalid sql timeout")
+		}
+	}
+	if cfg.SQL.MaxTimeout != "" {
+		if t.max, err = time.ParseDuration(cfg.SQL.MaxTimeout); err != nil {
+			return t, errors.Wrap(err, "invalid sql max timeout")
+		}
+	}
+	return t, nil
+}
+
+// resolve returns the timeout of a query, requested is either a duration such as "30s" or milliseconds. A requested
+// timeout of zero means the default, so that clients cannot lift the default timeout.
+func (t queryTimeouts) resolve(requested string) (time.Duration, error) {
+	d := t.def
+	if requested != "" {
+		var (
+			r   time.Duration
+			err error
+		)
+		if ms, perr := strconv.ParseInt(requested, 10, 64); perr == nil {
+			r = time.Duration(ms) * time.Millisecond
+		} else if r, err = time.ParseDuration(requested); err != nil {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "invalid statement timeout "+requested)
+		}
+		if r < 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "invalid statement timeout "+requested)
+		}
+		if r > 0 {
+			d = r
+		}
+	}
+	if t.max > 0 && (d == 0 || d > t.max) {
+		d = t.max
+	}
+	return d, nil
+}
+
+type runningQuery struct {
+	info   apigen.RunningQuery
+	cancel context.CancelCauseFunc
+}
+
+// queryRegistry tracks the queries in flight so that clients can list and cancel them. Queries are identified by ids
+// generated by the server, since request ids can be set by clients and are not unique.
+type queryRegistry struct {
+	mu      sync.Mutex
+	queries map[string]*runningQuery
+}
+
+func newQueryRegistry() *queryRegistry {
+	return &queryRegistry{queries: make(map[string]*runningQuery)}
+}
+
+func (r *queryRegistry) add(q *runningQuery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries[q.info.Id] = q
+}
+
+func (r *queryRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.queries, id)
+}
+
+// list returns the queries issued with the given API key, or every query if all is set, oldest first.
+func (r *queryRegistry) list(apiKeyID string, all bool) []apigen.RunningQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := []apigen.RunningQuery{}
+	for _, q := range r.queries {
+		if all || apiKeyIDOf(q.info) == apiKeyID {
+			ret = append(ret, q.info)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].StartedAt.Before(ret[j].StartedAt) })
+	return ret
+}
+
+// cancel cancels a query issued with the given API key, or any query if all is set.
+func (r *queryRegistry) cancel(id string, apiKeyID string, all bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q, ok := r.queries[id]
+	if !ok || (!all && apiKeyIDOf(q.info) != apiKeyID) {
+		return false
+	}
+	q.cancel(rw.ErrQueryCanceled)
+	return true
+}
+
+func apiKeyIDOf(q apigen.RunningQuery) string {
+	if q.ApiKeyId == nil {
+		return ""
+	}
+	return *q.ApiKeyId
+}
+
+// queryContext returns the context of a query of the request. The context is cancelled when the statement timeout
+// expires, when the query is cancelled through the API or when the client disconnects. done must be called once
+// the query is finished.
+func (h *Handler) queryContext(c *fiber.Ctx, sql string, timeout *string) (context.Context, func(), error) {
+	requested := c.Get(HeaderStatementTimeout)
+	if requested == "" && timeout != nil {
+		requested = *timeout
+	}
+	d, err := h.timeouts.resolve(requested)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(c.Context())
+	q := &runningQuery{
+		info: apigen.RunningQuery{
+			Id:        uuid.NewString(),
+			Sql:       sql,
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	c.Set(HeaderQueryID, q.info.Id)
+	if id := APIKeyID(c); id != "" {
+		q.info.ApiKeyId = &id
+	}
+
+	var cancelTimeout context.CancelFunc = func() {}
+	if d > 0 {
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, d, rw.ErrQueryTimeout)
+		deadline := q.info.StartedAt.Add(d)
+		q.info.Deadline = &deadline
+	}
+
+	h.queries.add(q)
+	stopWatch := watchDisconnect(c.Context().Conn(), func() { cancel(errClientGone) })
+
+	return ctx, func() {
+		stopWatch()
+		h.queries.remove(q.info.Id)
+		cancelTimeout()
+		cancel(nil)
+	}, nil
+}
+
+// watchDisconnect calls cancel if the client closes the connection before stop is called.
+func watchDisconnect(conn net.Conn, cancel func()) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if connClosed(conn) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return sync.OnceFunc(func() { close(done) })
+}
+
+// queryError returns the reason the query context was cancelled instead of the error of the query, if any.
+func queryError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, rw.ErrQueryTimeout) || errors.Is(cause, rw.ErrQueryCanceled) {
+		return cause
+	}
+	return err
+}
+
+// queryOwner returns the API key whose queries a request can list and cancel, and whether it is an admin key that
+// can list and cancel every query. Anonymous requests are rejected, since they would all share the same queries.
+func (h *Handler) queryOwner(c *fiber.Ctx) (string, bool, error) {
+	id := APIKeyID(c)
+	if id == "" {
+		return "", false, fiber.NewError(fiber.StatusUnauthorized, "an api key is required to manage running queries")
+	}
+	return id, h.adminKeys[id], nil
+}
+
+func (h *Handler) ListQueries(c *fiber.Ctx) error {
+	id, all, err := h.queryOwner(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(h.queries.list(id, all))
+}
+
+func (h *Handler) CancelQuery(c *fiber.Ctx, id string) error {
+	owner, all, err := h.queryOwner(c)
+	if err != nil {
+		return err
+	}
+	if !h.queries.cancel(id, owner, all) {
+		return fiber.NewError(fiber.StatusNotFound, "no running query "+id)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
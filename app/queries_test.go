@@ -0,0 +1,123 @@
+package app
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTimeoutsResolve(t *testing.T) {
+	timeouts := queryTimeouts{def: 30 * time.Second, max: time.Minute}
+	testCases := []struct {
+		requested string
+		expected  time.Duration
+		err       bool
+	}{
+		{"", 30 * time.Second, false},
+		{"1500", 1500 * time.Millisecond, false},
+		{"10s", 10 * time.Second, false},
+		{"1h", time.Minute, false},
+		{"0", 30 * time.Second, false},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tc := range testCases {
+		d, err := timeouts.resolve(tc.requested)
+		if tc.err {
+			require.Error(t, err, tc.requested)
+			continue
+		}
+		require.NoError(t, err, tc.requested)
+		require.Equal(t, tc.expected, d, tc.requested)
+	}
+
+	d, err := queryTimeouts{}.resolve("")
+	require.NoError(t, err)
+	require.Zero(t, d)
+
+	// without a default, zero is capped to the maximum like a missing timeout
+	d, err = queryTimeouts{max: time.Minute}.resolve("0")
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, d)
+}
+
+func TestQueryRegistryScope(t *testing.T) {
+	r := newQueryRegistry()
+	canceled := map[string]bool{}
+	add := func(id, apiKeyID string, startedAt time.Time) {
+		q := &runningQuery{
+			info:   apigen.RunningQuery{Id: id, Sql: "SELECT 1", StartedAt: startedAt},
+			cancel: func(error) { canceled[id] = true },
+		}
+		if apiKeyID != "" {
+			q.info.ApiKeyId = &apiKeyID
+		}
+		r.add(q)
+	}
+	now := time.Now()
+	add("q1", "alice", now)
+	add("q2", "bob", now.Add(time.Second))
+	add("q3", "alice", now.Add(2*time.Second))
+
+	ids := func(qs []apigen.RunningQuery) []string {
+		ret := make([]string, len(qs))
+		for i, q := range qs {
+			ret[i] = q.Id
+		}
+		return ret
+	}
+	require.Equal(t, []string{"q1", "q3"}, ids(r.list("alice", false)))
+	require.Equal(t, []string{"q1", "q2", "q3"}, ids(r.list("admin", true)))
+
+	require.False(t, r.cancel("q2", "alice", false))
+	require.False(t, canceled["q2"])
+	require.True(t, r.cancel("q2", "admin", true))
+	require.True(t, canceled["q2"])
+	require.True(t, r.cancel("q3", "alice", false))
+	require.False(t, r.cancel("missing", "admin", true))
+}
+
+func TestQueryContextIDs(t *testing.T) {
+	h := &Handler{queries: newQueryRegistry()}
+	var (
+		both, afterFirst []apigen.RunningQuery
+		header           string
+	)
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Get("/", func(c *fiber.Ctx) error {
+		// the queries of requests with the same request id must not replace each other
+		_, done1, err := h.queryContext(c, "SELECT 1", nil)
+		if err != nil {
+			return err
+		}
+		_, done2, err := h.queryContext(c, "SELECT 2", nil)
+		if err != nil {
+			return err
+		}
+		both = h.queries.list("", false)
+		done1()
+		afterFirst = h.queries.list("", false)
+		header = c.GetRespHeader(HeaderQueryID)
+		done2()
+		return nil
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "same")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, both, 2)
+	require.Len(t, afterFirst, 1)
+	require.Equal(t, "SELECT 2", afterFirst[0].Sql)
+	require.Equal(t, afterFirst[0].Id, header)
+	require.Equal(t, header, resp.Header.Get(HeaderQueryID))
+	require.Empty(t, h.queries.list("", false))
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"net/url"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+)
+
+const MIMEJSONSchema = "application/schema+json"
+
+// invalidComponentChars are the characters not allowed in the keys of OpenAPI components.
+var invalidComponentChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+var errorSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"code", "message"},
+	"properties": map[string]any{
+		"code":      map[string]any{"type": "string"},
+		"message":   map[string]any{"type": "string"},
+		"sqlstate":  map[string]any{"type": "string"},
+		"requestId": map[string]any{"type": "string"},
+	},
+}
+
+func (h *Handler) GetEventSchema(c *fiber.Ctx, name string) error {
+	r, ok := h.watcher.Relation(name)
+	if !ok {
+		return errors.Wrapf(rw.ErrRelationNotFound, "no table %s", h.searchPath.QualifiedName(name))
+	}
+	if err := c.JSON(rw.JSONSchema(r, h.es.EnrichedColumns(r))); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, MIMEJSONSchema)
+	return nil
+}
+
+// GetEventsOpenAPI generates the OpenAPI document of the ingestible tables from the catalog cache, so that it
+// follows the tables as they change.
+func (h *Handler) GetEventsOpenAPI(c *fiber.Ctx) error {
+	var (
+		paths   = make(map[string]any)
+		schemas = map[string]any{"Error": errorSchema}
+	)
+	for _, r := range h.watcher.Relations() {
+		name := r.QualifiedName()
+		if !h.es.Ingestible(name) {
+			continue
+		}
+
+		schema := rw.JSONSchema(r, h.es.EnrichedColumns(r))
+		delete(schema, "$schema")
+		key := invalidComponentChars.ReplaceAllString(name, "_")
+		schemas[key] = schema
+
+		ref := map[string]any{"$ref": "#/components/schemas/" + key}
+		paths["/events/"+url.PathEscape(name)] = map[string]any{
+			"post": map[string]any{
+				"summary":     "Ingest events into " + name,
+				"operationId": "ingest_" + invalidComponentChars.ReplaceAllString(r.Schema+"_"+r.Name, "_"),
+				"requestBody": map[string]any{
+					"required":    true,
+					"description": "A single event, or one event per line with " + rw.MIMENDJSON,
+					"content": map[string]any{
+						fiber.MIMEApplicationJSON: map[string]any{"schema": ref},
+						rw.MIMENDJSON:             map[string]any{"schema": ref},
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{"description": "The events are ingested"},
+					"default": map[string]any{
+						"description": "The request failed",
+						"content": map[string]any{
+							fiber.MIMEApplicationJSON: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return c.JSON(map[string]any{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": rw.JSONSchemaDialect,
+		"info": map[string]any{
+			"title":   "Event API ingestion",
+			"version": "1.0",
+		},
+		"servers":    []any{map[string]any{"url": "/v1"}},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	})
+}
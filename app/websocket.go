@@ -0,0 +1,367 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/querycache"
+	"github.com/risingwavelabs/events-api/pkg/ratelimit"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"go.uber.org/zap"
+)
+
+const (
+	WSMessageIngest      = "ingest"
+	WSMessageSubscribe   = "subscribe"
+	WSMessageUnsubscribe = "unsubscribe"
+	WSMessageAck         = "ack"
+	WSMessageChange      = "change"
+	WSMessageError       = "error"
+
+	// wsReadLimit is the maximum size of a client message, the same as the HTTP body limit.
+	wsReadLimit = 50 * 1024 * 1024
+	// wsMaxInflight is the number of ingest messages of a connection processed concurrently, so that messages sent
+	// back to back are inserted in the same batch. Reading stops until an ack is sent once it is reached.
+	wsMaxInflight = 64
+	wsWriteWait   = 10 * time.Second
+	wsPongWait    = 60 * time.Second
+	wsPingPeriod  = wsPongWait * 9 / 10
+)
+
+// WSRequest is a message sent by the client.
+type WSRequest struct {
+	Type string `json:"type"`
+	// Seq is echoed in the ack or error reply of the message.
+	Seq *int64 `json:"seq,omitempty"`
+	// ID identifies a subscription of the connection, it defaults to the table name.
+	ID     string            `json:"id,omitempty"`
+	Table  string            `json:"table,omitempty"`
+	Events []json.RawMessage `json:"events,omitempty"`
+	// Op is the operation of the events that do not set one, see rw.EventOpField.
+	Op    string `json:"op,omitempty"`
+	Since *int64 `json:"since,omitempty"`
+}
+
+// WSReply is a message sent by the server.
+type WSReply struct {
+	Type  string `json:"type"`
+	Seq   *int64 `json:"seq,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Code is the error code, the same as in the body of HTTP error responses.
+	Code string `json:"code,omitempty"`
+	// RetryAfter is the number of seconds to wait before retrying a rate limited message.
+	RetryAfter int64 `json:"retry_after,omitempty"`
+
+	*rw.ChangeEvent
+}
+
+// changeStream is a subscription of a connection, a *rw.Subscription outside of tests.
+type changeStream interface {
+	Fetch(ctx context.Context) ([]rw.ChangeEvent, error)
+	Close()
+}
+
+// WebSocketHandler serves /v1/ws, where clients ingest events and receive the changes of subscriptions over a
+// single long-lived connection.
+type WebSocketHandler struct {
+	gctx    *gctx.GlobalContext
+	es      *rw.EventService
+	limiter *ratelimit.Limiter
+	cache   *querycache.Cache
+	log     *zap.Logger
+
+	// open opens a subscription on a table or materialized view for an API key, checking it against the SQL policy
+	// of the key like GET /v1/subscribe/{name} does
+	open func(ctx context.Context, apiKeyID, relation string, since *int64) (changeStream, error)
+}
+
+func NewWebSocketHandler(gctx *gctx.GlobalContext, es *rw.EventService, subscriber *rw.Subscriber, limiter *ratelimit.Limiter, cache *querycache.Cache, log *zap.Logger) *WebSocketHandler {
+	return &WebSocketHandler{
+		gctx:    gctx,
+		es:      es,
+		limiter: limiter,
+		cache:   cache,
+		log:     log.Named("websocket"),
+		open: func(ctx context.Context, apiKeyID, relation string, since *int64) (changeStream, error) {
+			sub, err := subscriber.Open(ctx, apiKeyID, relation, since)
+			if err != nil {
+				return nil, err
+			}
+			return sub, nil
+		},
+	}
+}
+
+// Upgrade rejects requests that are not WebSocket handshakes, the API key and rate limit middlewares have already
+// run for the handshake request.
+func (h *WebSocketHandler) Upgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	return c.Next()
+}
+
+func (h *WebSocketHandler) Handler() fiber.Handler {
+	return websocket.New(h.serve)
+}
+
+type wsConn struct {
+	conn    *websocket.Conn
+	apiKey  string
+	writeMu sync.Mutex
+
+	// meta describes the upgrade request, the messages of the connection are ingested with it
+	meta rw.RequestMeta
+
+	mu   sync.Mutex
+	subs map[string]context.CancelFunc
+}
+
+func (c *wsConn) write(reply WSReply) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(reply)
+}
+
+func (c *wsConn) writeControl(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteControl(messageType, data, time.Now().Add(wsWriteWait))
+}
+
+func (c *wsConn) replyError(seq *int64, id string, err error) {
+	_, body := errorBody(err, "")
+	_ = c.write(WSReply{Type: WSMessageError, Seq: seq, ID: id, Error: body.Message, Code: body.Code})
+}
+
+func (h *WebSocketHandler) serve(conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(h.gctx.Context())
+	defer cancel()
+
+	apiKey, _ := conn.Locals(localsAPIKeyID).(string)
+	ip, ok := conn.Locals(localsClientIP).(string)
+	if !ok {
+		ip = conn.IP()
+	}
+	rid, _ := conn.Locals(requestid.ConfigDefault.ContextKey.(string)).(string)
+	c := &wsConn{
+		conn:   conn,
+		apiKey: apiKey,
+		meta: rw.RequestMeta{
+			RequestID: rid,
+			ClientIP:  ip,
+			UserAgent: conn.Headers(fiber.HeaderUserAgent),
+			APIKeyID:  apiKey,
+		},
+		subs: make(map[string]context.CancelFunc),
+	}
+	log := h.log.With(zap.String("ip", ip), zap.String("api_key_id", apiKey))
+
+	conn.SetReadLimit(wsReadLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// pings keep idle connections alive through proxies, closing the connection on shutdown unblocks the reader
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.writeControl(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				if h.gctx.Context().Err() != nil {
+					_ = c.writeControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+					_ = conn.Close()
+				}
+				return
+			}
+		}
+	}()
+
+	inflight := make(chan struct{}, wsMaxInflight)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) && ctx.Err() == nil {
+				log.Info("websocket connection closed", zap.Error(err))
+			}
+			break
+		}
+
+		var req WSRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.replyError(nil, "", fiber.NewError(fiber.StatusBadRequest, "invalid message: "+err.Error()))
+			continue
+		}
+
+		switch req.Type {
+		case WSMessageIngest:
+			inflight <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-inflight }()
+				h.ingest(ctx, c, &req, log)
+			}()
+		case WSMessageSubscribe:
+			h.subscribe(ctx, c, &req, &wg, log)
+		case WSMessageUnsubscribe:
+			id := subscriptionID(&req)
+			c.mu.Lock()
+			stop, ok := c.subs[id]
+			delete(c.subs, id)
+			c.mu.Unlock()
+			if !ok {
+				c.replyError(req.Seq, id, fiber.NewError(fiber.StatusNotFound, "no subscription "+id))
+				continue
+			}
+			stop()
+			_ = c.write(WSReply{Type: WSMessageAck, Seq: req.Seq, ID: id})
+		default:
+			c.replyError(req.Seq, "", fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown message type %q", req.Type)))
+		}
+	}
+
+	cancel()
+}
+
+func subscriptionID(req *WSRequest) string {
+	if req.ID != "" {
+		return req.ID
+	}
+	return req.Table
+}
+
+// ingest inserts the events of the message and acks it once they are flushed.
+func (h *WebSocketHandler) ingest(ctx context.Context, c *wsConn, req *WSRequest, log *zap.Logger) {
+	if req.Table == "" {
+		c.replyError(req.Seq, "", fiber.NewError(fiber.StatusBadRequest, "table is required"))
+		return
+	}
+	raw := bytes.Join(bytesOf(req.Events), []byte("\n"))
+	meta := c.meta
+	meta.ReceivedAt = time.Now()
+
+	if h.limiter.Enabled() {
+		d := h.limiter.Allow(ratelimit.Request{
+			APIKey: c.apiKey,
+			IP:     meta.ClientIP,
+			Table:  h.es.QualifiedName(req.Table),
+			Events: int64(len(req.Events)),
+			Bytes:  int64(len(raw)),
+		})
+		if !d.Allowed {
+			_ = c.write(WSReply{
+				Type:       WSMessageError,
+				Seq:        req.Seq,
+				Error:      d.Reason,
+				Code:       CodeRateLimited,
+				RetryAfter: int64(math.Ceil(d.RetryAfter.Seconds())),
+			})
+			return
+		}
+	}
+
+	if len(req.Events) > 0 {
+		op, err := rw.ParseEventOp(req.Op)
+		if err != nil {
+			c.replyError(req.Seq, "", err)
+			return
+		}
+		if err := h.es.IngestEvent(ctx, req.Table, op, raw, &meta); err != nil {
+			log.Debug("failed to ingest websocket message", zap.String("table", req.Table), zap.Error(err))
+			c.replyError(req.Seq, "", err)
+			return
+		}
+		h.cache.Invalidate(req.Table)
+	}
+	_ = c.write(WSReply{Type: WSMessageAck, Seq: req.Seq})
+}
+
+func bytesOf(events []json.RawMessage) [][]byte {
+	ret := make([][]byte, len(events))
+	for i, e := range events {
+		ret[i] = e
+	}
+	return ret
+}
+
+// subscribe opens the subscription and forwards its changes until the client unsubscribes or disconnects.
+func (h *WebSocketHandler) subscribe(ctx context.Context, c *wsConn, req *WSRequest, wg *sync.WaitGroup, log *zap.Logger) {
+	id := subscriptionID(req)
+	if req.Table == "" {
+		c.replyError(req.Seq, id, fiber.NewError(fiber.StatusBadRequest, "table is required"))
+		return
+	}
+
+	subCtx, stop := context.WithCancel(ctx)
+	c.mu.Lock()
+	if _, ok := c.subs[id]; ok {
+		c.mu.Unlock()
+		stop()
+		c.replyError(req.Seq, id, fiber.NewError(fiber.StatusConflict, "subscription "+id+" already exists"))
+		return
+	}
+	c.subs[id] = stop
+	c.mu.Unlock()
+
+	sub, err := h.open(subCtx, c.apiKey, req.Table, req.Since)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.subs, id)
+		c.mu.Unlock()
+		stop()
+		c.replyError(req.Seq, id, err)
+		return
+	}
+	if err := c.write(WSReply{Type: WSMessageAck, Seq: req.Seq, ID: id}); err != nil {
+		stop()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer stop()
+		defer sub.Close()
+
+		for {
+			events, err := sub.Fetch(subCtx)
+			if err != nil {
+				if subCtx.Err() == nil {
+					log.Warn("subscription interrupted", zap.String("relation", req.Table), zap.Error(err))
+					c.mu.Lock()
+					delete(c.subs, id)
+					c.mu.Unlock()
+					c.replyError(nil, id, err)
+				}
+				return
+			}
+			for i := range events {
+				if err := c.write(WSReply{Type: WSMessageChange, ID: id, ChangeEvent: &events[i]}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeStream struct {
+	changes chan rw.ChangeEvent
+	closed  chan struct{}
+}
+
+func (s *fakeStream) Fetch(ctx context.Context) ([]rw.ChangeEvent, error) {
+	select {
+	case e := <-s.changes:
+		return []rw.ChangeEvent{e}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeStream) Close() {
+	close(s.closed)
+}
+
+// serveWebSocket serves the handler with the given API key and returns a connected client.
+func serveWebSocket(t *testing.T, h *WebSocketHandler, apiKey string) *websocket.Conn {
+	app := fiber.New()
+	app.Use("/v1/ws", func(c *fiber.Ctx) error {
+		c.Locals(localsAPIKeyID, apiKey)
+		return c.Next()
+	}, h.Upgrade)
+	app.Get("/v1/ws", h.Handler())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/v1/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func roundTrip(t *testing.T, conn *websocket.Conn, req WSRequest) WSReply {
+	require.NoError(t, conn.WriteJSON(req))
+	return readReply(t, conn)
+}
+
+func readReply(t *testing.T, conn *websocket.Conn) WSReply {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var reply WSReply
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func seq(n int64) *int64 {
+	return &n
+}
+
+func TestWebSocketSubscribe(t *testing.T) {
+	gc := gctx.New(zap.NewNop())
+	defer gc.Cancel()
+
+	streams := make(chan *fakeStream, 1)
+	h := &WebSocketHandler{gctx: gc, log: zap.NewNop()}
+	h.open = func(_ context.Context, apiKeyID, relation string, _ *int64) (changeStream, error) {
+		switch relation {
+		case "secret":
+			return nil, &statement.Violation{Kind: "SUBSCRIPTION", Reason: "only read-only statements are allowed"}
+		case "clicks", "clicks_per_minute": // a table and a materialized view
+		default:
+			return nil, errors.Wrapf(rw.ErrRelationNotFound, "no relation %s to subscribe to", relation)
+		}
+		if apiKeyID != "key-1" {
+			return nil, fiber.ErrUnauthorized
+		}
+		s := &fakeStream{changes: make(chan rw.ChangeEvent, 1), closed: make(chan struct{})}
+		streams <- s
+		return s, nil
+	}
+	conn := serveWebSocket(t, h, "key-1")
+
+	reply := roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(1), Table: "clicks"})
+	require.Equal(t, WSMessageAck, reply.Type)
+	require.Equal(t, int64(1), *reply.Seq)
+	require.Equal(t, "clicks", reply.ID)
+	stream := <-streams
+
+	stream.changes <- rw.ChangeEvent{Op: rw.OpInsert, Timestamp: 42, Row: map[string]any{"id": "a"}}
+	reply = readReply(t, conn)
+	require.Equal(t, WSMessageChange, reply.Type)
+	require.Equal(t, "clicks", reply.ID)
+	require.Nil(t, reply.Seq)
+	require.Equal(t, int64(42), reply.Timestamp)
+	require.Equal(t, "a", reply.Row["id"])
+
+	// the id defaults to the table, so the same table cannot be subscribed twice without an id
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(2), Table: "clicks"})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, int64(2), *reply.Seq)
+	require.Equal(t, CodeConflict, reply.Code)
+
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(3), Table: "secret"})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, int64(3), *reply.Seq)
+	require.Equal(t, "secret", reply.ID)
+	require.Equal(t, CodeForbidden, reply.Code)
+
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(31), Table: "missing"})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, "missing", reply.ID)
+	require.Equal(t, CodeRelationNotFound, reply.Code)
+
+	// materialized views are subscribed to like tables
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(32), Table: "clicks_per_minute"})
+	require.Equal(t, WSMessageAck, reply.Type)
+	require.Equal(t, int64(32), *reply.Seq)
+	require.Equal(t, "clicks_per_minute", reply.ID)
+	view := <-streams
+	view.changes <- rw.ChangeEvent{Op: rw.OpUpdateInsert, Timestamp: 43, Row: map[string]any{"n": int64(2)}}
+	reply = readReply(t, conn)
+	require.Equal(t, WSMessageChange, reply.Type)
+	require.Equal(t, "clicks_per_minute", reply.ID)
+	require.Equal(t, rw.OpUpdateInsert, reply.Op)
+
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageUnsubscribe, Seq: seq(4), ID: "clicks"})
+	require.Equal(t, WSMessageAck, reply.Type)
+	require.Equal(t, int64(4), *reply.Seq)
+	require.Equal(t, "clicks", reply.ID)
+	select {
+	case <-stream.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed after unsubscribe")
+	}
+
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageUnsubscribe, Seq: seq(5), ID: "clicks"})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, int64(5), *reply.Seq)
+	require.Equal(t, CodeNotFound, reply.Code)
+
+	// a rejected subscription does not take the id, and an unsubscribed id can be reused
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(6), Table: "clicks"})
+	require.Equal(t, WSMessageAck, reply.Type)
+	require.Equal(t, int64(6), *reply.Seq)
+	<-streams
+}
+
+func TestWebSocketUnknownMessage(t *testing.T) {
+	gc := gctx.New(zap.NewNop())
+	defer gc.Cancel()
+
+	conn := serveWebSocket(t, &WebSocketHandler{gctx: gc, log: zap.NewNop()}, "")
+
+	reply := roundTrip(t, conn, WSRequest{Type: "publish", Seq: seq(7)})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, int64(7), *reply.Seq)
+	require.Equal(t, CodeBadRequest, reply.Code)
+
+	reply = roundTrip(t, conn, WSRequest{Type: WSMessageSubscribe, Seq: seq(8)})
+	require.Equal(t, WSMessageError, reply.Type)
+	require.Equal(t, int64(8), *reply.Seq)
+	require.Equal(t, CodeBadRequest, reply.Code)
+}
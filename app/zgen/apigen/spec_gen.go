@@ -12,11 +12,54 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/oapi-codegen/runtime"
 )
 
+// Defines values for ChangeEventOp.
+const (
+	Delete       ChangeEventOp = "delete"
+	Insert       ChangeEventOp = "insert"
+	UpdateDelete ChangeEventOp = "update_delete"
+	UpdateInsert ChangeEventOp = "update_insert"
+)
+
+// BatchRequest defines model for BatchRequest.
+type BatchRequest struct {
+	Statements []QueryRequest `json:"statements"`
+
+	// Transaction Run the statements in a transaction, which is rolled back if one fails. RisingWave only supports read-only transactions.
+	Transaction *bool `json:"transaction,omitempty"`
+}
+
+// BatchResponse defines model for BatchResponse.
+type BatchResponse struct {
+	Error *Error `json:"error,omitempty"`
+
+	// FailedIndex Index of the statement that failed, starting from 0
+	FailedIndex *int `json:"failedIndex,omitempty"`
+
+	// Results Results of the statements that succeeded, in order
+	Results []QueryResponse `json:"results"`
+}
+
+// ChangeEvent defines model for ChangeEvent.
+type ChangeEvent struct {
+	// Op Kind of the change, an update is delivered as an update_delete followed by an update_insert
+	Op ChangeEventOp `json:"op"`
+
+	// Row The row, the key is the column name and the value is the column value
+	Row map[string]interface{} `json:"row"`
+
+	// RwTimestamp Timestamp of the change, used to resume the subscription
+	RwTimestamp int64 `json:"rw_timestamp"`
+}
+
+// ChangeEventOp Kind of the change, an update is delivered as an update_delete followed by an update_insert
+type ChangeEventOp string
+
 // Column defines model for Column.
 type Column struct {
 	// IsHidden Whether the column is hidden
@@ -28,20 +71,119 @@ type Column struct {
 	// Name Name of the column
 	Name string `json:"name"`
 
-	// Type Data type of the column
+	// Type Data type of the column, e.g. bigint, numeric, timestamptz or integer[]
 	Type string `json:"type"`
 }
 
+// DDLJob defines model for DDLJob.
+type DDLJob struct {
+	// Done Whether the relation is created
+	Done bool `json:"done"`
+
+	// Id Id of the job, which is the id of the relation it creates
+	Id int64 `json:"id"`
+
+	// InitializedAt When the job started
+	InitializedAt *time.Time `json:"initializedAt,omitempty"`
+
+	// Progress Backfill progress, e.g. 42.5%
+	Progress string `json:"progress"`
+
+	// Statement The DDL statement, not reported once the job is done
+	Statement *string `json:"statement,omitempty"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	// Code Machine-readable error code: bad_request, invalid_event, query_failed, unauthorized, forbidden, canceled, not_found, relation_not_found, not_acceptable, conflict, payload_too_large, rate_limited, unavailable, timeout or internal
+	Code string `json:"code"`
+
+	// Message Human-readable description of the error
+	Message string `json:"message"`
+
+	// RequestId Id of the request, to be quoted when reporting the error
+	RequestId *string `json:"requestId,omitempty"`
+
+	// Sqlstate SQLSTATE reported by RisingWave, if the error comes from a statement
+	Sqlstate *string `json:"sqlstate,omitempty"`
+}
+
+// QueryRequest defines model for QueryRequest.
+type QueryRequest struct {
+	// Params Values of the query parameters. Integers, floats, strings, booleans, null and flat arrays of them are passed with their JSON type, objects are passed as JSON text.
+	Params *[]interface{} `json:"params,omitempty"`
+
+	// Sql SQL query to be executed, parameters are referenced as $1, $2, ...
+	Sql string `json:"sql"`
+}
+
 // QueryResponse defines model for QueryResponse.
 type QueryResponse struct {
-	Columns []Column `json:"columns"`
-
-	// Error Error message if the query failed
-	Error *string                  `json:"error,omitempty"`
-	Rows  []map[string]interface{} `json:"rows"`
+	Columns []Column                 `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
 
 	// RowsAffected Number of rows affected by the query
-	RowsAffected int32 `json:"rowsAffected"`
+	RowsAffected int64 `json:"rowsAffected"`
+}
+
+// RunningQuery defines model for RunningQuery.
+type RunningQuery struct {
+	// ApiKeyId Id of the API key the query was issued with
+	ApiKeyId *string `json:"apiKeyId,omitempty"`
+
+	// Deadline When the statement timeout expires, if the query has one
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// Id Id of the query, also returned in the X-Query-ID header of the response of the query
+	Id        string    `json:"id"`
+	Sql       string    `json:"sql"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Table defines model for Table.
+type Table struct {
+	Columns []TableColumn `json:"columns"`
+
+	// Definition The CREATE statement of the table
+	Definition string `json:"definition"`
+	Id         int32  `json:"id"`
+
+	// Ingestible Whether events can be ingested into the table through /events
+	Ingestible bool   `json:"ingestible"`
+	Name       string `json:"name"`
+	Schema     string `json:"schema"`
+
+	// Type Type of the relation, e.g. table
+	Type string `json:"type"`
+}
+
+// TableColumn defines model for TableColumn.
+type TableColumn struct {
+	// Ingestible Whether events set the column, system columns such as _row_id and _rw_timestamp are ignored
+	Ingestible   bool   `json:"ingestible"`
+	IsHidden     bool   `json:"isHidden"`
+	IsPrimaryKey bool   `json:"isPrimaryKey"`
+	Name         string `json:"name"`
+
+	// Type Data type of the column, e.g. bigint, numeric, timestamptz or integer[]
+	Type string `json:"type"`
+}
+
+// TableIngestion defines model for TableIngestion.
+type TableIngestion struct {
+	// Enabled Whether events can be ingested into the table
+	Enabled bool `json:"enabled"`
+
+	// Excluded Whether the ingest configuration excludes the table, it cannot be enabled at runtime
+	Excluded bool `json:"excluded"`
+
+	// Table Schema-qualified name of the table
+	Table string `json:"table"`
+}
+
+// TableIngestionUpdate defines model for TableIngestionUpdate.
+type TableIngestionUpdate struct {
+	Enabled bool `json:"enabled"`
 }
 
 // IngestEventJSONBody defines parameters for IngestEvent.
@@ -53,15 +195,51 @@ type IngestEventParams struct {
 	Name string `form:"name" json:"name"`
 }
 
+// IngestTableEventJSONBody defines parameters for IngestTableEvent.
+type IngestTableEventJSONBody = map[string]interface{}
+
 // ExecuteSQLTextBody defines parameters for ExecuteSQL.
 type ExecuteSQLTextBody = string
 
+// ExecuteSQLParams defines parameters for ExecuteSQL.
+type ExecuteSQLParams struct {
+	// Background Run a CREATE MATERIALIZED VIEW, CREATE INDEX, CREATE SINK or CREATE TABLE statement as background DDL. The response is sent as soon as the job is started, its progress is reported by /ddl/{id}.
+	Background *bool `form:"background,omitempty" json:"background,omitempty"`
+
+	// Timeout Statement timeout as a duration such as 30s or as milliseconds, the X-Statement-Timeout header takes precedence, 0 means the default timeout. The query is cancelled and 504 is returned once it expires.
+	Timeout *string `form:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// ExecuteSQLBatchParams defines parameters for ExecuteSQLBatch.
+type ExecuteSQLBatchParams struct {
+	// Timeout Statement timeout of the whole batch, as for /sql
+	Timeout *string `form:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// SubscribeParams defines parameters for Subscribe.
+type SubscribeParams struct {
+	// Since Replay changes from this rw_timestamp instead of starting from now. The Last-Event-ID header set by EventSource clients on reconnect takes precedence.
+	Since *int64 `form:"since,omitempty" json:"since,omitempty"`
+}
+
+// SetTableIngestionJSONRequestBody defines body for SetTableIngestion for application/json ContentType.
+type SetTableIngestionJSONRequestBody = TableIngestionUpdate
+
 // IngestEventJSONRequestBody defines body for IngestEvent for application/json ContentType.
 type IngestEventJSONRequestBody = IngestEventJSONBody
 
+// IngestTableEventJSONRequestBody defines body for IngestTableEvent for application/json ContentType.
+type IngestTableEventJSONRequestBody = IngestTableEventJSONBody
+
+// ExecuteSQLJSONRequestBody defines body for ExecuteSQL for application/json ContentType.
+type ExecuteSQLJSONRequestBody = QueryRequest
+
 // ExecuteSQLTextRequestBody defines body for ExecuteSQL for text/plain ContentType.
 type ExecuteSQLTextRequestBody = ExecuteSQLTextBody
 
+// ExecuteSQLBatchJSONRequestBody defines body for ExecuteSQLBatch for application/json ContentType.
+type ExecuteSQLBatchJSONRequestBody = BatchRequest
+
 // RequestEditorFn  is the function signature for the RequestEditor callback function
 type RequestEditorFn func(ctx context.Context, req *http.Request) error
 
@@ -135,22 +313,75 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 
 // The interface specification for the client above.
 type ClientInterface interface {
+	// RefreshCatalog request
+	RefreshCatalog(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetTableIngestion request
+	GetTableIngestion(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SetTableIngestionWithBody request with any body
+	SetTableIngestionWithBody(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	SetTableIngestion(ctx context.Context, name string, body SetTableIngestionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetDDLJob request
+	GetDDLJob(ctx context.Context, id int64, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// IngestEventWithBody request with any body
 	IngestEventWithBody(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	IngestEvent(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// IngestTableEventWithBody request with any body
+	IngestTableEventWithBody(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	IngestTableEvent(ctx context.Context, name string, body IngestTableEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// HealthCheck request
 	HealthCheck(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
 
+	// GetEventsOpenAPI request
+	GetEventsOpenAPI(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListQueries request
+	ListQueries(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// CancelQuery request
+	CancelQuery(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// RunSavedQuery request
+	RunSavedQuery(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetEventSchema request
+	GetEventSchema(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
 	// ExecuteSQLWithBody request with any body
-	ExecuteSQLWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+	ExecuteSQLWithBody(ctx context.Context, params *ExecuteSQLParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	ExecuteSQL(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	ExecuteSQLWithTextBody(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ExecuteSQLBatchWithBody request with any body
+	ExecuteSQLBatchWithBody(ctx context.Context, params *ExecuteSQLBatchParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	ExecuteSQLBatch(ctx context.Context, params *ExecuteSQLBatchParams, body ExecuteSQLBatchJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// Subscribe request
+	Subscribe(ctx context.Context, name string, params *SubscribeParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListTables request
+	ListTables(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetTable request
+	GetTable(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
 
-	ExecuteSQLWithTextBody(ctx context.Context, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+	// GetTableSchema request
+	GetTableSchema(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
 
-func (c *Client) IngestEventWithBody(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewIngestEventRequestWithBody(c.Server, params, contentType, body)
+func (c *Client) RefreshCatalog(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRefreshCatalogRequest(c.Server)
 	if err != nil {
 		return nil, err
 	}
@@ -161,8 +392,8 @@ func (c *Client) IngestEventWithBody(ctx context.Context, params *IngestEventPar
 	return c.Client.Do(req)
 }
 
-func (c *Client) IngestEvent(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewIngestEventRequest(c.Server, params, body)
+func (c *Client) GetTableIngestion(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetTableIngestionRequest(c.Server, name)
 	if err != nil {
 		return nil, err
 	}
@@ -173,8 +404,8 @@ func (c *Client) IngestEvent(ctx context.Context, params *IngestEventParams, bod
 	return c.Client.Do(req)
 }
 
-func (c *Client) HealthCheck(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewHealthCheckRequest(c.Server)
+func (c *Client) SetTableIngestionWithBody(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetTableIngestionRequestWithBody(c.Server, name, contentType, body)
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +416,8 @@ func (c *Client) HealthCheck(ctx context.Context, reqEditors ...RequestEditorFn)
 	return c.Client.Do(req)
 }
 
-func (c *Client) ExecuteSQLWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewExecuteSQLRequestWithBody(c.Server, contentType, body)
+func (c *Client) SetTableIngestion(ctx context.Context, name string, body SetTableIngestionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetTableIngestionRequest(c.Server, name, body)
 	if err != nil {
 		return nil, err
 	}
@@ -197,8 +428,8 @@ func (c *Client) ExecuteSQLWithBody(ctx context.Context, contentType string, bod
 	return c.Client.Do(req)
 }
 
-func (c *Client) ExecuteSQLWithTextBody(ctx context.Context, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewExecuteSQLRequestWithTextBody(c.Server, body)
+func (c *Client) GetDDLJob(ctx context.Context, id int64, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetDDLJobRequest(c.Server, id)
 	if err != nil {
 		return nil, err
 	}
@@ -209,344 +440,2240 @@ func (c *Client) ExecuteSQLWithTextBody(ctx context.Context, body ExecuteSQLText
 	return c.Client.Do(req)
 }
 
-// NewIngestEventRequest calls the generic IngestEvent builder with application/json body
-func NewIngestEventRequest(server string, params *IngestEventParams, body IngestEventJSONRequestBody) (*http.Request, error) {
-	var bodyReader io.Reader
-	buf, err := json.Marshal(body)
+func (c *Client) IngestEventWithBody(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIngestEventRequestWithBody(c.Server, params, contentType, body)
 	if err != nil {
 		return nil, err
 	}
-	bodyReader = bytes.NewReader(buf)
-	return NewIngestEventRequestWithBody(server, params, "application/json", bodyReader)
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewIngestEventRequestWithBody generates requests for IngestEvent with any type of body
-func NewIngestEventRequestWithBody(server string, params *IngestEventParams, contentType string, body io.Reader) (*http.Request, error) {
-	var err error
-
-	serverURL, err := url.Parse(server)
+func (c *Client) IngestEvent(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIngestEventRequest(c.Server, params, body)
 	if err != nil {
 		return nil, err
 	}
-
-	operationPath := fmt.Sprintf("/events")
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	queryURL, err := serverURL.Parse(operationPath)
+func (c *Client) IngestTableEventWithBody(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIngestTableEventRequestWithBody(c.Server, name, contentType, body)
 	if err != nil {
 		return nil, err
 	}
-
-	if params != nil {
-		queryValues := queryURL.Query()
-
-		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "name", runtime.ParamLocationQuery, params.Name); err != nil {
-			return nil, err
-		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
-			return nil, err
-		} else {
-			for k, v := range parsed {
-				for _, v2 := range v {
-					queryValues.Add(k, v2)
-				}
-			}
-		}
-
-		queryURL.RawQuery = queryValues.Encode()
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	req, err := http.NewRequest("POST", queryURL.String(), body)
+func (c *Client) IngestTableEvent(ctx context.Context, name string, body IngestTableEventJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIngestTableEventRequest(c.Server, name, body)
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Add("Content-Type", contentType)
-
-	return req, nil
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewHealthCheckRequest generates requests for HealthCheck
-func NewHealthCheckRequest(server string) (*http.Request, error) {
-	var err error
-
-	serverURL, err := url.Parse(server)
+func (c *Client) HealthCheck(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewHealthCheckRequest(c.Server)
 	if err != nil {
 		return nil, err
 	}
-
-	operationPath := fmt.Sprintf("/healthz")
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	queryURL, err := serverURL.Parse(operationPath)
+func (c *Client) GetEventsOpenAPI(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEventsOpenAPIRequest(c.Server)
 	if err != nil {
 		return nil, err
 	}
-
-	req, err := http.NewRequest("GET", queryURL.String(), nil)
-	if err != nil {
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
 		return nil, err
 	}
-
-	return req, nil
+	return c.Client.Do(req)
 }
 
-// NewExecuteSQLRequestWithTextBody calls the generic ExecuteSQL builder with text/plain body
-func NewExecuteSQLRequestWithTextBody(server string, body ExecuteSQLTextRequestBody) (*http.Request, error) {
-	var bodyReader io.Reader
-	bodyReader = strings.NewReader(string(body))
-	return NewExecuteSQLRequestWithBody(server, "text/plain", bodyReader)
+func (c *Client) ListQueries(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListQueriesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewExecuteSQLRequestWithBody generates requests for ExecuteSQL with any type of body
-func NewExecuteSQLRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
-	var err error
-
-	serverURL, err := url.Parse(server)
+func (c *Client) CancelQuery(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewCancelQueryRequest(c.Server, name)
 	if err != nil {
 		return nil, err
 	}
-
-	operationPath := fmt.Sprintf("/sql")
-	if operationPath[0] == '/' {
-		operationPath = "." + operationPath
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
+	return c.Client.Do(req)
+}
 
-	queryURL, err := serverURL.Parse(operationPath)
+func (c *Client) RunSavedQuery(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRunSavedQueryRequest(c.Server, name)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
 
-	req, err := http.NewRequest("POST", queryURL.String(), body)
+func (c *Client) GetEventSchema(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetEventSchemaRequest(c.Server, name)
 	if err != nil {
 		return nil, err
 	}
-
-	req.Header.Add("Content-Type", contentType)
-
-	return req, nil
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
-	for _, r := range c.RequestEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
+func (c *Client) ExecuteSQLWithBody(ctx context.Context, params *ExecuteSQLParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewExecuteSQLRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
 	}
-	for _, r := range additionalEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
 	}
-	return nil
+	return c.Client.Do(req)
 }
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
+func (c *Client) ExecuteSQL(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewExecuteSQLRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+func (c *Client) ExecuteSQLWithTextBody(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewExecuteSQLRequestWithTextBody(c.Server, params, body)
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
 }
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
+func (c *Client) ExecuteSQLBatchWithBody(ctx context.Context, params *ExecuteSQLBatchParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewExecuteSQLBatchRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ExecuteSQLBatch(ctx context.Context, params *ExecuteSQLBatchParams, body ExecuteSQLBatchJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewExecuteSQLBatchRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) Subscribe(ctx context.Context, name string, params *SubscribeParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSubscribeRequest(c.Server, name, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListTables(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListTablesRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetTable(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetTableRequest(c.Server, name)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetTableSchema(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetTableSchemaRequest(c.Server, name)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewRefreshCatalogRequest generates requests for RefreshCatalog
+func NewRefreshCatalogRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/catalog/refresh")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetTableIngestionRequest generates requests for GetTableIngestion
+func NewGetTableIngestionRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/tables/%s/ingestion", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSetTableIngestionRequest calls the generic SetTableIngestion builder with application/json body
+func NewSetTableIngestionRequest(server string, name string, body SetTableIngestionJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewSetTableIngestionRequestWithBody(server, name, "application/json", bodyReader)
+}
+
+// NewSetTableIngestionRequestWithBody generates requests for SetTableIngestion with any type of body
+func NewSetTableIngestionRequestWithBody(server string, name string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/tables/%s/ingestion", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetDDLJobRequest generates requests for GetDDLJob
+func NewGetDDLJobRequest(server string, id int64) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "id", runtime.ParamLocationPath, id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/ddl/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewIngestEventRequest calls the generic IngestEvent builder with application/json body
+func NewIngestEventRequest(server string, params *IngestEventParams, body IngestEventJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewIngestEventRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewIngestEventRequestWithBody generates requests for IngestEvent with any type of body
+func NewIngestEventRequestWithBody(server string, params *IngestEventParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/events")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithLocation("form", true, "name", runtime.ParamLocationQuery, params.Name); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewIngestTableEventRequest calls the generic IngestTableEvent builder with application/json body
+func NewIngestTableEventRequest(server string, name string, body IngestTableEventJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewIngestTableEventRequestWithBody(server, name, "application/json", bodyReader)
+}
+
+// NewIngestTableEventRequestWithBody generates requests for IngestTableEvent with any type of body
+func NewIngestTableEventRequestWithBody(server string, name string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/events/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewHealthCheckRequest generates requests for HealthCheck
+func NewHealthCheckRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/healthz")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEventsOpenAPIRequest generates requests for GetEventsOpenAPI
+func NewGetEventsOpenAPIRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/openapi.json")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListQueriesRequest generates requests for ListQueries
+func NewListQueriesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/queries")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewCancelQueryRequest generates requests for CancelQuery
+func NewCancelQueryRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/queries/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewRunSavedQueryRequest generates requests for RunSavedQuery
+func NewRunSavedQueryRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/queries/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetEventSchemaRequest generates requests for GetEventSchema
+func NewGetEventSchemaRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/schemas/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewExecuteSQLRequest calls the generic ExecuteSQL builder with application/json body
+func NewExecuteSQLRequest(server string, params *ExecuteSQLParams, body ExecuteSQLJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewExecuteSQLRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewExecuteSQLRequestWithTextBody calls the generic ExecuteSQL builder with text/plain body
+func NewExecuteSQLRequestWithTextBody(server string, params *ExecuteSQLParams, body ExecuteSQLTextRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	bodyReader = strings.NewReader(string(body))
+	return NewExecuteSQLRequestWithBody(server, params, "text/plain", bodyReader)
+}
+
+// NewExecuteSQLRequestWithBody generates requests for ExecuteSQL with any type of body
+func NewExecuteSQLRequestWithBody(server string, params *ExecuteSQLParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/sql")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Background != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "background", runtime.ParamLocationQuery, *params.Background); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Timeout != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "timeout", runtime.ParamLocationQuery, *params.Timeout); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewExecuteSQLBatchRequest calls the generic ExecuteSQLBatch builder with application/json body
+func NewExecuteSQLBatchRequest(server string, params *ExecuteSQLBatchParams, body ExecuteSQLBatchJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewExecuteSQLBatchRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewExecuteSQLBatchRequestWithBody generates requests for ExecuteSQLBatch with any type of body
+func NewExecuteSQLBatchRequestWithBody(server string, params *ExecuteSQLBatchParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/sql/batch")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Timeout != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "timeout", runtime.ParamLocationQuery, *params.Timeout); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewSubscribeRequest generates requests for Subscribe
+func NewSubscribeRequest(server string, name string, params *SubscribeParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/subscribe/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Since != nil {
+
+			if queryFrag, err := runtime.StyleParamWithLocation("form", true, "since", runtime.ParamLocationQuery, *params.Since); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListTablesRequest generates requests for ListTables
+func NewListTablesRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/tables")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetTableRequest generates requests for GetTable
+func NewGetTableRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/tables/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetTableSchemaRequest generates requests for GetTableSchema
+func NewGetTableSchemaRequest(server string, name string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "name", runtime.ParamLocationPath, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/tables/%s/schema", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
 			return err
 		}
-		c.Server = newBaseURL.String()
-		return nil
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// RefreshCatalogWithResponse request
+	RefreshCatalogWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*RefreshCatalogResponse, error)
+
+	// GetTableIngestionWithResponse request
+	GetTableIngestionWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableIngestionResponse, error)
+
+	// SetTableIngestionWithBodyWithResponse request with any body
+	SetTableIngestionWithBodyWithResponse(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetTableIngestionResponse, error)
+
+	SetTableIngestionWithResponse(ctx context.Context, name string, body SetTableIngestionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetTableIngestionResponse, error)
+
+	// GetDDLJobWithResponse request
+	GetDDLJobWithResponse(ctx context.Context, id int64, reqEditors ...RequestEditorFn) (*GetDDLJobResponse, error)
+
+	// IngestEventWithBodyWithResponse request with any body
+	IngestEventWithBodyWithResponse(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestEventResponse, error)
+
+	IngestEventWithResponse(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestEventResponse, error)
+
+	// IngestTableEventWithBodyWithResponse request with any body
+	IngestTableEventWithBodyWithResponse(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestTableEventResponse, error)
+
+	IngestTableEventWithResponse(ctx context.Context, name string, body IngestTableEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestTableEventResponse, error)
+
+	// HealthCheckWithResponse request
+	HealthCheckWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthCheckResponse, error)
+
+	// GetEventsOpenAPIWithResponse request
+	GetEventsOpenAPIWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetEventsOpenAPIResponse, error)
+
+	// ListQueriesWithResponse request
+	ListQueriesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListQueriesResponse, error)
+
+	// CancelQueryWithResponse request
+	CancelQueryWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*CancelQueryResponse, error)
+
+	// RunSavedQueryWithResponse request
+	RunSavedQueryWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*RunSavedQueryResponse, error)
+
+	// GetEventSchemaWithResponse request
+	GetEventSchemaWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetEventSchemaResponse, error)
+
+	// ExecuteSQLWithBodyWithResponse request with any body
+	ExecuteSQLWithBodyWithResponse(ctx context.Context, params *ExecuteSQLParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error)
+
+	ExecuteSQLWithResponse(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLJSONRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error)
+
+	ExecuteSQLWithTextBodyWithResponse(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error)
+
+	// ExecuteSQLBatchWithBodyWithResponse request with any body
+	ExecuteSQLBatchWithBodyWithResponse(ctx context.Context, params *ExecuteSQLBatchParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLBatchResponse, error)
+
+	ExecuteSQLBatchWithResponse(ctx context.Context, params *ExecuteSQLBatchParams, body ExecuteSQLBatchJSONRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLBatchResponse, error)
+
+	// SubscribeWithResponse request
+	SubscribeWithResponse(ctx context.Context, name string, params *SubscribeParams, reqEditors ...RequestEditorFn) (*SubscribeResponse, error)
+
+	// ListTablesWithResponse request
+	ListTablesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListTablesResponse, error)
+
+	// GetTableWithResponse request
+	GetTableWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableResponse, error)
+
+	// GetTableSchemaWithResponse request
+	GetTableSchemaWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableSchemaResponse, error)
+}
+
+type RefreshCatalogResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r RefreshCatalogResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RefreshCatalogResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetTableIngestionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TableIngestion
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetTableIngestionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetTableIngestionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SetTableIngestionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TableIngestion
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetTableIngestionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetTableIngestionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetDDLJobResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DDLJob
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetDDLJobResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetDDLJobResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type IngestEventResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r IngestEventResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r IngestEventResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type IngestTableEventResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r IngestTableEventResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r IngestTableEventResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type HealthCheckResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+// Status returns HTTPResponse.Status
+func (r HealthCheckResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r HealthCheckResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEventsOpenAPIResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *map[string]interface{}
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEventsOpenAPIResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEventsOpenAPIResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListQueriesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]RunningQuery
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ListQueriesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListQueriesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type CancelQueryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r CancelQueryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r CancelQueryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RunSavedQueryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *QueryResponse
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r RunSavedQueryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RunSavedQueryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetEventSchemaResponse struct {
+	Body                     []byte
+	HTTPResponse             *http.Response
+	ApplicationschemaJSON200 *map[string]interface{}
+	JSONDefault              *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetEventSchemaResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetEventSchemaResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ExecuteSQLResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *QueryResponse
+	JSON202      *DDLJob
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ExecuteSQLResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ExecuteSQLResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ExecuteSQLBatchResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *BatchResponse
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ExecuteSQLBatchResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ExecuteSQLBatchResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SubscribeResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SubscribeResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SubscribeResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListTablesResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]Table
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r ListTablesResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListTablesResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetTableResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *Table
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetTableResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetTableResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetTableSchemaResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *[]TableColumn
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetTableSchemaResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetTableSchemaResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// RefreshCatalogWithResponse request returning *RefreshCatalogResponse
+func (c *ClientWithResponses) RefreshCatalogWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*RefreshCatalogResponse, error) {
+	rsp, err := c.RefreshCatalog(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRefreshCatalogResponse(rsp)
+}
+
+// GetTableIngestionWithResponse request returning *GetTableIngestionResponse
+func (c *ClientWithResponses) GetTableIngestionWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableIngestionResponse, error) {
+	rsp, err := c.GetTableIngestion(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetTableIngestionResponse(rsp)
+}
+
+// SetTableIngestionWithBodyWithResponse request with arbitrary body returning *SetTableIngestionResponse
+func (c *ClientWithResponses) SetTableIngestionWithBodyWithResponse(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetTableIngestionResponse, error) {
+	rsp, err := c.SetTableIngestionWithBody(ctx, name, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetTableIngestionResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetTableIngestionWithResponse(ctx context.Context, name string, body SetTableIngestionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetTableIngestionResponse, error) {
+	rsp, err := c.SetTableIngestion(ctx, name, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetTableIngestionResponse(rsp)
+}
+
+// GetDDLJobWithResponse request returning *GetDDLJobResponse
+func (c *ClientWithResponses) GetDDLJobWithResponse(ctx context.Context, id int64, reqEditors ...RequestEditorFn) (*GetDDLJobResponse, error) {
+	rsp, err := c.GetDDLJob(ctx, id, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetDDLJobResponse(rsp)
+}
+
+// IngestEventWithBodyWithResponse request with arbitrary body returning *IngestEventResponse
+func (c *ClientWithResponses) IngestEventWithBodyWithResponse(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestEventResponse, error) {
+	rsp, err := c.IngestEventWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIngestEventResponse(rsp)
+}
+
+func (c *ClientWithResponses) IngestEventWithResponse(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestEventResponse, error) {
+	rsp, err := c.IngestEvent(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIngestEventResponse(rsp)
+}
+
+// IngestTableEventWithBodyWithResponse request with arbitrary body returning *IngestTableEventResponse
+func (c *ClientWithResponses) IngestTableEventWithBodyWithResponse(ctx context.Context, name string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestTableEventResponse, error) {
+	rsp, err := c.IngestTableEventWithBody(ctx, name, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIngestTableEventResponse(rsp)
+}
+
+func (c *ClientWithResponses) IngestTableEventWithResponse(ctx context.Context, name string, body IngestTableEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestTableEventResponse, error) {
+	rsp, err := c.IngestTableEvent(ctx, name, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIngestTableEventResponse(rsp)
+}
+
+// HealthCheckWithResponse request returning *HealthCheckResponse
+func (c *ClientWithResponses) HealthCheckWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthCheckResponse, error) {
+	rsp, err := c.HealthCheck(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHealthCheckResponse(rsp)
+}
+
+// GetEventsOpenAPIWithResponse request returning *GetEventsOpenAPIResponse
+func (c *ClientWithResponses) GetEventsOpenAPIWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetEventsOpenAPIResponse, error) {
+	rsp, err := c.GetEventsOpenAPI(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEventsOpenAPIResponse(rsp)
+}
+
+// ListQueriesWithResponse request returning *ListQueriesResponse
+func (c *ClientWithResponses) ListQueriesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListQueriesResponse, error) {
+	rsp, err := c.ListQueries(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListQueriesResponse(rsp)
+}
+
+// CancelQueryWithResponse request returning *CancelQueryResponse
+func (c *ClientWithResponses) CancelQueryWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*CancelQueryResponse, error) {
+	rsp, err := c.CancelQuery(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCancelQueryResponse(rsp)
+}
+
+// RunSavedQueryWithResponse request returning *RunSavedQueryResponse
+func (c *ClientWithResponses) RunSavedQueryWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*RunSavedQueryResponse, error) {
+	rsp, err := c.RunSavedQuery(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRunSavedQueryResponse(rsp)
+}
+
+// GetEventSchemaWithResponse request returning *GetEventSchemaResponse
+func (c *ClientWithResponses) GetEventSchemaWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetEventSchemaResponse, error) {
+	rsp, err := c.GetEventSchema(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetEventSchemaResponse(rsp)
+}
+
+// ExecuteSQLWithBodyWithResponse request with arbitrary body returning *ExecuteSQLResponse
+func (c *ClientWithResponses) ExecuteSQLWithBodyWithResponse(ctx context.Context, params *ExecuteSQLParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error) {
+	rsp, err := c.ExecuteSQLWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExecuteSQLResponse(rsp)
+}
+
+func (c *ClientWithResponses) ExecuteSQLWithResponse(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLJSONRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error) {
+	rsp, err := c.ExecuteSQL(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExecuteSQLResponse(rsp)
+}
+
+func (c *ClientWithResponses) ExecuteSQLWithTextBodyWithResponse(ctx context.Context, params *ExecuteSQLParams, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error) {
+	rsp, err := c.ExecuteSQLWithTextBody(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExecuteSQLResponse(rsp)
+}
+
+// ExecuteSQLBatchWithBodyWithResponse request with arbitrary body returning *ExecuteSQLBatchResponse
+func (c *ClientWithResponses) ExecuteSQLBatchWithBodyWithResponse(ctx context.Context, params *ExecuteSQLBatchParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLBatchResponse, error) {
+	rsp, err := c.ExecuteSQLBatchWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExecuteSQLBatchResponse(rsp)
+}
+
+func (c *ClientWithResponses) ExecuteSQLBatchWithResponse(ctx context.Context, params *ExecuteSQLBatchParams, body ExecuteSQLBatchJSONRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLBatchResponse, error) {
+	rsp, err := c.ExecuteSQLBatch(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExecuteSQLBatchResponse(rsp)
+}
+
+// SubscribeWithResponse request returning *SubscribeResponse
+func (c *ClientWithResponses) SubscribeWithResponse(ctx context.Context, name string, params *SubscribeParams, reqEditors ...RequestEditorFn) (*SubscribeResponse, error) {
+	rsp, err := c.Subscribe(ctx, name, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSubscribeResponse(rsp)
+}
+
+// ListTablesWithResponse request returning *ListTablesResponse
+func (c *ClientWithResponses) ListTablesWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*ListTablesResponse, error) {
+	rsp, err := c.ListTables(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListTablesResponse(rsp)
+}
+
+// GetTableWithResponse request returning *GetTableResponse
+func (c *ClientWithResponses) GetTableWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableResponse, error) {
+	rsp, err := c.GetTable(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetTableResponse(rsp)
+}
+
+// GetTableSchemaWithResponse request returning *GetTableSchemaResponse
+func (c *ClientWithResponses) GetTableSchemaWithResponse(ctx context.Context, name string, reqEditors ...RequestEditorFn) (*GetTableSchemaResponse, error) {
+	rsp, err := c.GetTableSchema(ctx, name, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetTableSchemaResponse(rsp)
+}
+
+// ParseRefreshCatalogResponse parses an HTTP response from a RefreshCatalogWithResponse call
+func ParseRefreshCatalogResponse(rsp *http.Response) (*RefreshCatalogResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RefreshCatalogResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetTableIngestionResponse parses an HTTP response from a GetTableIngestionWithResponse call
+func ParseGetTableIngestionResponse(rsp *http.Response) (*GetTableIngestionResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetTableIngestionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TableIngestion
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseSetTableIngestionResponse parses an HTTP response from a SetTableIngestionWithResponse call
+func ParseSetTableIngestionResponse(rsp *http.Response) (*SetTableIngestionResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SetTableIngestionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TableIngestion
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetDDLJobResponse parses an HTTP response from a GetDDLJobWithResponse call
+func ParseGetDDLJobResponse(rsp *http.Response) (*GetDDLJobResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetDDLJobResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DDLJob
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseIngestEventResponse parses an HTTP response from a IngestEventWithResponse call
+func ParseIngestEventResponse(rsp *http.Response) (*IngestEventResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &IngestEventResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
 	}
+
+	return response, nil
 }
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// IngestEventWithBodyWithResponse request with any body
-	IngestEventWithBodyWithResponse(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestEventResponse, error)
+// ParseIngestTableEventResponse parses an HTTP response from a IngestTableEventWithResponse call
+func ParseIngestTableEventResponse(rsp *http.Response) (*IngestTableEventResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
 
-	IngestEventWithResponse(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestEventResponse, error)
+	response := &IngestTableEventResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
 
-	// HealthCheckWithResponse request
-	HealthCheckWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthCheckResponse, error)
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
 
-	// ExecuteSQLWithBodyWithResponse request with any body
-	ExecuteSQLWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error)
+	}
 
-	ExecuteSQLWithTextBodyWithResponse(ctx context.Context, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error)
+	return response, nil
 }
 
-type IngestEventResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-}
+// ParseHealthCheckResponse parses an HTTP response from a HealthCheckWithResponse call
+func ParseHealthCheckResponse(rsp *http.Response) (*HealthCheckResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
 
-// Status returns HTTPResponse.Status
-func (r IngestEventResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+	response := &HealthCheckResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
 	}
-	return http.StatusText(0)
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r IngestEventResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParseGetEventsOpenAPIResponse parses an HTTP response from a GetEventsOpenAPIWithResponse call
+func ParseGetEventsOpenAPIResponse(rsp *http.Response) (*GetEventsOpenAPIResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type HealthCheckResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
+	response := &GetEventsOpenAPIResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r HealthCheckResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// ParseListQueriesResponse parses an HTTP response from a ListQueriesWithResponse call
+func ParseListQueriesResponse(rsp *http.Response) (*ListQueriesResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
+
+	response := &ListQueriesResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []RunningQuery
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r HealthCheckResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParseCancelQueryResponse parses an HTTP response from a CancelQueryWithResponse call
+func ParseCancelQueryResponse(rsp *http.Response) (*CancelQueryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
+
+	response := &CancelQueryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-type ExecuteSQLResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *QueryResponse
+// ParseRunSavedQueryResponse parses an HTTP response from a RunSavedQueryWithResponse call
+func ParseRunSavedQueryResponse(rsp *http.Response) (*RunSavedQueryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RunSavedQueryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest QueryResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	case rsp.StatusCode == 200:
+		// Content-type (text/csv) unsupported
+
+	}
+
+	return response, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r ExecuteSQLResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// ParseGetEventSchemaResponse parses an HTTP response from a GetEventSchemaWithResponse call
+func ParseGetEventSchemaResponse(rsp *http.Response) (*GetEventSchemaResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
+
+	response := &GetEventSchemaResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.ApplicationschemaJSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r ExecuteSQLResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParseExecuteSQLResponse parses an HTTP response from a ExecuteSQLWithResponse call
+func ParseExecuteSQLResponse(rsp *http.Response) (*ExecuteSQLResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
+
+	response := &ExecuteSQLResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest QueryResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest DDLJob
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	case rsp.StatusCode == 200:
+		// Content-type (text/csv) unsupported
+
+	}
+
+	return response, nil
 }
 
-// IngestEventWithBodyWithResponse request with arbitrary body returning *IngestEventResponse
-func (c *ClientWithResponses) IngestEventWithBodyWithResponse(ctx context.Context, params *IngestEventParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IngestEventResponse, error) {
-	rsp, err := c.IngestEventWithBody(ctx, params, contentType, body, reqEditors...)
-	if err != nil {
-		return nil, err
-	}
-	return ParseIngestEventResponse(rsp)
-}
+// ParseExecuteSQLBatchResponse parses an HTTP response from a ExecuteSQLBatchWithResponse call
+func ParseExecuteSQLBatchResponse(rsp *http.Response) (*ExecuteSQLBatchResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ExecuteSQLBatchResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest BatchResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
 
-func (c *ClientWithResponses) IngestEventWithResponse(ctx context.Context, params *IngestEventParams, body IngestEventJSONRequestBody, reqEditors ...RequestEditorFn) (*IngestEventResponse, error) {
-	rsp, err := c.IngestEvent(ctx, params, body, reqEditors...)
-	if err != nil {
-		return nil, err
 	}
-	return ParseIngestEventResponse(rsp)
+
+	return response, nil
 }
 
-// HealthCheckWithResponse request returning *HealthCheckResponse
-func (c *ClientWithResponses) HealthCheckWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*HealthCheckResponse, error) {
-	rsp, err := c.HealthCheck(ctx, reqEditors...)
+// ParseSubscribeResponse parses an HTTP response from a SubscribeWithResponse call
+func ParseSubscribeResponse(rsp *http.Response) (*SubscribeResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseHealthCheckResponse(rsp)
-}
 
-// ExecuteSQLWithBodyWithResponse request with arbitrary body returning *ExecuteSQLResponse
-func (c *ClientWithResponses) ExecuteSQLWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error) {
-	rsp, err := c.ExecuteSQLWithBody(ctx, contentType, body, reqEditors...)
-	if err != nil {
-		return nil, err
+	response := &SubscribeResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
 	}
-	return ParseExecuteSQLResponse(rsp)
-}
 
-func (c *ClientWithResponses) ExecuteSQLWithTextBodyWithResponse(ctx context.Context, body ExecuteSQLTextRequestBody, reqEditors ...RequestEditorFn) (*ExecuteSQLResponse, error) {
-	rsp, err := c.ExecuteSQLWithTextBody(ctx, body, reqEditors...)
-	if err != nil {
-		return nil, err
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
 	}
-	return ParseExecuteSQLResponse(rsp)
+
+	return response, nil
 }
 
-// ParseIngestEventResponse parses an HTTP response from a IngestEventWithResponse call
-func ParseIngestEventResponse(rsp *http.Response) (*IngestEventResponse, error) {
+// ParseListTablesResponse parses an HTTP response from a ListTablesWithResponse call
+func ParseListTablesResponse(rsp *http.Response) (*ListTablesResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &IngestEventResponse{
+	response := &ListTablesResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest []Table
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
 	return response, nil
 }
 
-// ParseHealthCheckResponse parses an HTTP response from a HealthCheckWithResponse call
-func ParseHealthCheckResponse(rsp *http.Response) (*HealthCheckResponse, error) {
+// ParseGetTableResponse parses an HTTP response from a GetTableWithResponse call
+func ParseGetTableResponse(rsp *http.Response) (*GetTableResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &HealthCheckResponse{
+	response := &GetTableResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest Table
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
 	return response, nil
 }
 
-// ParseExecuteSQLResponse parses an HTTP response from a ExecuteSQLWithResponse call
-func ParseExecuteSQLResponse(rsp *http.Response) (*ExecuteSQLResponse, error) {
+// ParseGetTableSchemaResponse parses an HTTP response from a GetTableSchemaWithResponse call
+func ParseGetTableSchemaResponse(rsp *http.Response) (*GetTableSchemaResponse, error) {
 	bodyBytes, err := io.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &ExecuteSQLResponse{
+	response := &GetTableSchemaResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest QueryResponse
+		var dest []TableColumn
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
 		response.JSON200 = &dest
 
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
 	}
 
 	return response, nil
@@ -554,15 +2681,60 @@ func ParseExecuteSQLResponse(rsp *http.Response) (*ExecuteSQLResponse, error) {
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Update the catalog cache of the server now
+	// (POST /admin/catalog/refresh)
+	RefreshCatalog(c *fiber.Ctx) error
+	// Tell whether events can be ingested into a table
+	// (GET /admin/tables/{name}/ingestion)
+	GetTableIngestion(c *fiber.Ctx, name string) error
+	// Enable or disable ingestion into a table
+	// (PUT /admin/tables/{name}/ingestion)
+	SetTableIngestion(c *fiber.Ctx, name string) error
+	// Get the progress of a background DDL job
+	// (GET /ddl/{id})
+	GetDDLJob(c *fiber.Ctx, id int64) error
 	// Ingest a new event
 	// (POST /events)
 	IngestEvent(c *fiber.Ctx, params IngestEventParams) error
+	// Ingest events into a table
+	// (POST /events/{name})
+	IngestTableEvent(c *fiber.Ctx, name string) error
 	// Health check endpoint
 	// (GET /healthz)
 	HealthCheck(c *fiber.Ctx) error
+	// Get the OpenAPI document of event ingestion
+	// (GET /openapi.json)
+	GetEventsOpenAPI(c *fiber.Ctx) error
+	// List the running queries issued with the API key of the request
+	// (GET /queries)
+	ListQueries(c *fiber.Ctx) error
+	// Cancel a running query issued with the API key of the request
+	// (DELETE /queries/{name})
+	CancelQuery(c *fiber.Ctx, name string) error
+	// Run a saved query
+	// (GET /queries/{name})
+	RunSavedQuery(c *fiber.Ctx, name string) error
+	// Get the JSON Schema of the events of a table
+	// (GET /schemas/{name})
+	GetEventSchema(c *fiber.Ctx, name string) error
 	// Execute a SQL query
 	// (POST /sql)
-	ExecuteSQL(c *fiber.Ctx) error
+	ExecuteSQL(c *fiber.Ctx, params ExecuteSQLParams) error
+	// Execute a batch of SQL statements
+	// (POST /sql/batch)
+	ExecuteSQLBatch(c *fiber.Ctx, params ExecuteSQLBatchParams) error
+	// Stream the changes of a table or materialized view as Server-Sent Events
+	// (GET /subscribe/{name})
+	Subscribe(c *fiber.Ctx, name string, params SubscribeParams) error
+	// List the tables
+	// (GET /tables)
+	ListTables(c *fiber.Ctx) error
+	// Describe a table
+	// (GET /tables/{name})
+	GetTable(c *fiber.Ctx, name string) error
+	// Describe the columns of a table
+	// (GET /tables/{name}/schema)
+	GetTableSchema(c *fiber.Ctx, name string) error
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -572,6 +2744,60 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc fiber.Handler
 
+// RefreshCatalog operation middleware
+func (siw *ServerInterfaceWrapper) RefreshCatalog(c *fiber.Ctx) error {
+
+	return siw.Handler.RefreshCatalog(c)
+}
+
+// GetTableIngestion operation middleware
+func (siw *ServerInterfaceWrapper) GetTableIngestion(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.GetTableIngestion(c, name)
+}
+
+// SetTableIngestion operation middleware
+func (siw *ServerInterfaceWrapper) SetTableIngestion(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.SetTableIngestion(c, name)
+}
+
+// GetDDLJob operation middleware
+func (siw *ServerInterfaceWrapper) GetDDLJob(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int64
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Params("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter id: %w", err).Error())
+	}
+
+	return siw.Handler.GetDDLJob(c, id)
+}
+
 // IngestEvent operation middleware
 func (siw *ServerInterfaceWrapper) IngestEvent(c *fiber.Ctx) error {
 
@@ -604,16 +2830,211 @@ func (siw *ServerInterfaceWrapper) IngestEvent(c *fiber.Ctx) error {
 	return siw.Handler.IngestEvent(c, params)
 }
 
+// IngestTableEvent operation middleware
+func (siw *ServerInterfaceWrapper) IngestTableEvent(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.IngestTableEvent(c, name)
+}
+
 // HealthCheck operation middleware
 func (siw *ServerInterfaceWrapper) HealthCheck(c *fiber.Ctx) error {
 
 	return siw.Handler.HealthCheck(c)
 }
 
+// GetEventsOpenAPI operation middleware
+func (siw *ServerInterfaceWrapper) GetEventsOpenAPI(c *fiber.Ctx) error {
+
+	return siw.Handler.GetEventsOpenAPI(c)
+}
+
+// ListQueries operation middleware
+func (siw *ServerInterfaceWrapper) ListQueries(c *fiber.Ctx) error {
+
+	return siw.Handler.ListQueries(c)
+}
+
+// CancelQuery operation middleware
+func (siw *ServerInterfaceWrapper) CancelQuery(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.CancelQuery(c, name)
+}
+
+// RunSavedQuery operation middleware
+func (siw *ServerInterfaceWrapper) RunSavedQuery(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.RunSavedQuery(c, name)
+}
+
+// GetEventSchema operation middleware
+func (siw *ServerInterfaceWrapper) GetEventSchema(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.GetEventSchema(c, name)
+}
+
 // ExecuteSQL operation middleware
 func (siw *ServerInterfaceWrapper) ExecuteSQL(c *fiber.Ctx) error {
 
-	return siw.Handler.ExecuteSQL(c)
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExecuteSQLParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "background" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "background", query, &params.Background)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter background: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "timeout" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "timeout", query, &params.Timeout)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter timeout: %w", err).Error())
+	}
+
+	return siw.Handler.ExecuteSQL(c, params)
+}
+
+// ExecuteSQLBatch operation middleware
+func (siw *ServerInterfaceWrapper) ExecuteSQLBatch(c *fiber.Ctx) error {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExecuteSQLBatchParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "timeout" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "timeout", query, &params.Timeout)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter timeout: %w", err).Error())
+	}
+
+	return siw.Handler.ExecuteSQLBatch(c, params)
+}
+
+// Subscribe operation middleware
+func (siw *ServerInterfaceWrapper) Subscribe(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SubscribeParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", query, &params.Since)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter since: %w", err).Error())
+	}
+
+	return siw.Handler.Subscribe(c, name, params)
+}
+
+// ListTables operation middleware
+func (siw *ServerInterfaceWrapper) ListTables(c *fiber.Ctx) error {
+
+	return siw.Handler.ListTables(c)
+}
+
+// GetTable operation middleware
+func (siw *ServerInterfaceWrapper) GetTable(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.GetTable(c, name)
+}
+
+// GetTableSchema operation middleware
+func (siw *ServerInterfaceWrapper) GetTableSchema(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Params("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter name: %w", err).Error())
+	}
+
+	return siw.Handler.GetTableSchema(c, name)
 }
 
 // FiberServerOptions provides options for the Fiber server.
@@ -637,10 +3058,40 @@ func RegisterHandlersWithOptions(router fiber.Router, si ServerInterface, option
 		router.Use(fiber.Handler(m))
 	}
 
+	router.Post(options.BaseURL+"/admin/catalog/refresh", wrapper.RefreshCatalog)
+
+	router.Get(options.BaseURL+"/admin/tables/:name/ingestion", wrapper.GetTableIngestion)
+
+	router.Put(options.BaseURL+"/admin/tables/:name/ingestion", wrapper.SetTableIngestion)
+
+	router.Get(options.BaseURL+"/ddl/:id", wrapper.GetDDLJob)
+
 	router.Post(options.BaseURL+"/events", wrapper.IngestEvent)
 
+	router.Post(options.BaseURL+"/events/:name", wrapper.IngestTableEvent)
+
 	router.Get(options.BaseURL+"/healthz", wrapper.HealthCheck)
 
+	router.Get(options.BaseURL+"/openapi.json", wrapper.GetEventsOpenAPI)
+
+	router.Get(options.BaseURL+"/queries", wrapper.ListQueries)
+
+	router.Delete(options.BaseURL+"/queries/:name", wrapper.CancelQuery)
+
+	router.Get(options.BaseURL+"/queries/:name", wrapper.RunSavedQuery)
+
+	router.Get(options.BaseURL+"/schemas/:name", wrapper.GetEventSchema)
+
 	router.Post(options.BaseURL+"/sql", wrapper.ExecuteSQL)
 
+	router.Post(options.BaseURL+"/sql/batch", wrapper.ExecuteSQLBatch)
+
+	router.Get(options.BaseURL+"/subscribe/:name", wrapper.Subscribe)
+
+	router.Get(options.BaseURL+"/tables", wrapper.ListTables)
+
+	router.Get(options.BaseURL+"/tables/:name", wrapper.GetTable)
+
+	router.Get(options.BaseURL+"/tables/:name/schema", wrapper.GetTableSchema)
+
 }
@@ -25,6 +25,211 @@ type Debug struct {
 	Enable bool `yaml:"enable"`
 }
 
+type APIKey struct {
+	// (Required) The identifier of the key. It is used in logs, rate limit buckets and per-key overrides, never the secret itself.
+	ID string `yaml:"id"`
+
+	// (Required) The secret value clients send in the X-API-Key header or as a bearer token.
+	Key string `yaml:"key"`
+}
+
+type RateLimitRule struct {
+	// (Required) What the limit is keyed by, one of "apikey", "ip" or "table".
+	By string `yaml:"by"`
+
+	// (Optional) Only apply the rule to this key value, e.g. "public.clickstream". Empty applies the rule to every value separately.
+	Match string `yaml:"match"`
+
+	// (Optional) Sustained rates. Zero means unlimited.
+	RequestsPerSec float64 `yaml:"requestspersec"`
+	EventsPerSec   float64 `yaml:"eventspersec"`
+	BytesPerSec    float64 `yaml:"bytespersec"`
+
+	// (Optional) The number of seconds worth of tokens a bucket can accumulate, default is 1.
+	Burst float64 `yaml:"burst"`
+
+	// (Optional) Daily quotas, reset at 00:00 UTC. Zero means unlimited.
+	DailyRequests int64 `yaml:"dailyrequests"`
+	DailyEvents   int64 `yaml:"dailyevents"`
+	DailyBytes    int64 `yaml:"dailybytes"`
+}
+
+type RateLimit struct {
+	Enable bool `yaml:"enable"`
+
+	Rules []RateLimitRule `yaml:"rules"`
+
+	// (Optional) The RisingWave table used to persist daily quota usage across restarts. Usage is kept in memory only if empty.
+	PersistTable string `yaml:"persisttable"`
+}
+
+type SQLPolicy struct {
+	// (Optional) "readwrite" (default) or "readonly". The read-only mode only allows SELECT, SHOW, DESCRIBE, EXPLAIN and VALUES statements.
+	Mode string `yaml:"mode"`
+
+	// (Optional) If set, only these statement kinds are allowed, e.g. "SELECT", "CREATE MATERIALIZED VIEW".
+	Allow []string `yaml:"allow"`
+
+	// (Optional) Statement kinds that are always rejected, e.g. "DROP", "ALTER SYSTEM", "CREATE SINK". A kind also matches more specific kinds, so "DROP" matches "DROP TABLE".
+	Deny []string `yaml:"deny"`
+}
+
+type SQL struct {
+	// The default policy of the SQL endpoint.
+	SQLPolicy `yaml:",inline"`
+
+	// (Optional) Per API key overrides keyed by the API key id. Unset fields are inherited from the default policy.
+	Keys map[string]SQLPolicy `yaml:"keys"`
+
+	// (Optional) The statement timeout of queries that do not set one, e.g. "30s". Queries have no timeout by default.
+	Timeout string `yaml:"timeout"`
+
+	// (Optional) The maximum statement timeout a query can set, e.g. "10m". Longer or missing timeouts are capped to it.
+	MaxTimeout string `yaml:"maxtimeout"`
+}
+
+type Subscription struct {
+	// (Optional) How long RisingWave retains changes of the subscriptions created by the API, default is "1D". Clients
+	// can only resume within the retention, and subscriptions no client read for as long are dropped. Accepts seconds,
+	// minutes, hours or days, e.g. "12h".
+	Retention string `yaml:"retention"`
+
+	// (Optional) The maximum number of concurrent subscriptions, each holds a dedicated connection, default is 100.
+	MaxSubscriptions int `yaml:"maxsubscriptions"`
+}
+
+type SavedQueryParam struct {
+	// (Required) The name of the query string parameter.
+	Name string `yaml:"name"`
+
+	// (Optional) One of "string" (default), "integer", "number", "boolean", "date" or "timestamp", or an array of them such as "integer[]" given by repeating the parameter.
+	Type string `yaml:"type"`
+
+	// (Optional) Whether the parameter must be given. Optional parameters without a default are NULL.
+	Required bool `yaml:"required"`
+
+	// (Optional) The value used when the parameter is not given.
+	Default *string `yaml:"default"`
+
+	// (Optional) The bounds of integer and number parameters.
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+
+	// (Optional) The allowed values of string parameters.
+	Enum []string `yaml:"enum"`
+}
+
+type SavedQuery struct {
+	// (Required) The name of the query, it is served at /v1/queries/{name}.
+	Name string `yaml:"name"`
+
+	// (Required) A single read-only statement. The parameters are referenced as $1, $2, ... in the order they are declared.
+	SQL string `yaml:"sql"`
+
+	Description string `yaml:"description"`
+
+	Params []SavedQueryParam `yaml:"params"`
+
+	// (Optional) The number of seconds clients may cache the result for, sent in the Cache-Control header. Zero means no caching.
+	MaxAge int `yaml:"maxage"`
+
+	// (Optional) The API key ids allowed to run the query. Everyone, including anonymous clients, can run it if empty.
+	Keys []string `yaml:"keys"`
+}
+
+type SavedQueries struct {
+	Queries []SavedQuery `yaml:"queries"`
+
+	// (Optional) A RisingWave table holding more saved queries, created if it does not exist. It is reloaded periodically, the queries of the configuration take precedence.
+	Table string `yaml:"table"`
+}
+
+type QueryCache struct {
+	// (Optional) Cache the JSON results of SELECT statements of the SQL endpoint and of saved queries, default is false.
+	Enable bool `yaml:"enable"`
+
+	// (Optional) How long a result is served from the cache, default is "5s".
+	TTL string `yaml:"ttl"`
+
+	// (Optional) The maximum number of cached results, default is 10000.
+	MaxEntries int `yaml:"maxentries"`
+
+	// (Optional) The maximum total size of cached results in bytes, default is 64MB.
+	MaxBytes int64 `yaml:"maxbytes"`
+
+	// (Optional) Results larger than this number of bytes are not cached, default is 1MB.
+	MaxEntryBytes int64 `yaml:"maxentrybytes"`
+}
+
+type Catalog struct {
+	// (Optional) How often the catalog of RisingWave is checked for new, altered and dropped tables, default is "1s". DDL run through the API is picked up immediately.
+	PollInterval string `yaml:"pollinterval"`
+
+	// (Optional) The schemas unqualified table names are resolved against, in order, default is ["public"]. It is also the search_path of the connections to RisingWave.
+	SearchPath []string `yaml:"searchpath"`
+}
+
+type Ingest struct {
+	// (Optional) Glob patterns of the tables events can be ingested into, e.g. "analytics.*". A pattern without a dot matches the table name in any schema. All tables are included if empty.
+	Include []string `yaml:"include"`
+
+	// (Optional) Glob patterns of the tables events are never ingested into, they take precedence over include. System schemas and the tables of savedqueries and ratelimit are always excluded.
+	Exclude []string `yaml:"exclude"`
+
+	// (Optional) How long the bulk insert operator of a table is kept after its last ingest, default is "10m". Operators are created on the first ingest into a table.
+	IdleTimeout string `yaml:"idletimeout"`
+
+	// (Optional) Drop events whose dedup column value was already ingested into the table within a time window.
+	Dedup []DedupRule `yaml:"dedup"`
+
+	// (Optional) Columns filled by the server from the request instead of the events. Columns named _ingest_ followed by one of the sources, e.g. _ingest_received_at, are filled without a rule.
+	Enrich []EnrichRule `yaml:"enrich"`
+}
+
+type DedupRule struct {
+	// (Required) The table the rule applies to.
+	Table string `yaml:"table"`
+
+	// (Required) The column identifying an event, events with a value already seen within the window are dropped. Events without a value are never dropped.
+	Column string `yaml:"column"`
+
+	// (Optional) How long a value is remembered, default is "10m". A value is remembered for at least the window and at most twice as long.
+	Window string `yaml:"window"`
+
+	// (Optional) The maximum number of values remembered per window, default is 1000000. The window is shortened when it is reached.
+	MaxKeys int `yaml:"maxkeys"`
+}
+
+type EnrichRule struct {
+	// (Required) The table the rule applies to.
+	Table string `yaml:"table"`
+
+	// (Required) The columns filled by the server instead of the events, keyed by column name. The values are one of "received_at", "request_id", "client_ip", "user_agent" or "api_key", one of "browser", "browser_version", "os", "os_version" or "device" parsed from the User-Agent, or one of "country", "region", "city", "asn" or "as_org" looked up from the IP in the geoip databases.
+	Columns map[string]string `yaml:"columns"`
+
+	// (Optional) The column holding the User-Agent the browser, os and device sources are parsed from, the User-Agent header by default.
+	UserAgentColumn string `yaml:"useragentcolumn"`
+
+	// (Optional) The column holding the IP the geoip sources are looked up from, the client IP by default.
+	IPColumn string `yaml:"ipcolumn"`
+}
+
+type GeoIP struct {
+	// (Optional) The path of a MaxMind-format city database such as GeoLite2-City.mmdb, for the country, region and city enrichment sources. A country database only gives the country.
+	CityDB string `yaml:"citydb"`
+
+	// (Optional) The path of a MaxMind-format ASN database such as GeoLite2-ASN.mmdb, for the asn and as_org enrichment sources.
+	ASNDB string `yaml:"asndb"`
+}
+
+type Idempotency struct {
+	// (Optional) How long the result of a request with an Idempotency-Key header is kept, default is "24h".
+	TTL string `yaml:"ttl"`
+
+	// (Optional) The maximum number of results kept, the oldest ones are dropped beyond it, default is 100000.
+	MaxEntries int `yaml:"maxentries"`
+}
+
 type Config struct {
 	// (Optional) The host of the anclax server.
 	Host string `yaml:"host"`
@@ -36,6 +241,33 @@ type Config struct {
 	Rw *Rw `yaml:"rw"`
 
 	Debug Debug `yaml:"debug"`
+
+	// (Optional) The API keys accepted by the server. Requests without a key are treated as anonymous.
+	APIKeys []APIKey `yaml:"apikeys"`
+
+	RateLimit RateLimit `yaml:"ratelimit"`
+
+	SQL SQL `yaml:"sql"`
+
+	Subscription Subscription `yaml:"subscription"`
+
+	SavedQueries SavedQueries `yaml:"savedqueries"`
+
+	QueryCache QueryCache `yaml:"querycache"`
+
+	Catalog Catalog `yaml:"catalog"`
+
+	Ingest Ingest `yaml:"ingest"`
+
+	Idempotency Idempotency `yaml:"idempotency"`
+
+	GeoIP GeoIP `yaml:"geoip"`
+
+	// (Optional) The IPs or CIDRs of the proxies in front of the server, e.g. "10.0.0.0/8". The client IP of requests from them is taken from X-Forwarded-For. The IP of the connection is used if empty.
+	TrustedProxies []string `yaml:"trustedproxies"`
+
+	// (Optional) The API key ids allowed to use the admin endpoints under /v1/admin. The admin endpoints are disabled if empty.
+	AdminKeys []string `yaml:"adminkeys"`
 }
 
 const (
@@ -0,0 +1,137 @@
+package enrich
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/closer"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"go.uber.org/zap"
+)
+
+// Location is where an IP is located by the GeoIP databases. Unknown fields are empty.
+type Location struct {
+	// Country is the ISO 3166-1 code of the country, e.g. "US".
+	Country string
+	// Region is the English name of the first subdivision of the country, e.g. a state.
+	Region string
+	City   string
+	ASN    uint
+	ASOrg  string
+}
+
+type cityRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type asnRecord struct {
+	ASN   uint   `maxminddb:"autonomous_system_number"`
+	ASOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+// GeoIP locates IPs with local MaxMind-format databases, such as GeoLite2-City and GeoLite2-ASN.
+type GeoIP struct {
+	// mu guards the databases, which are unmapped once closed
+	mu   sync.RWMutex
+	city *maxminddb.Reader
+	asn  *maxminddb.Reader
+	log  *zap.Logger
+}
+
+func NewGeoIP(cfg *config.Config, log *zap.Logger, cm *closer.CloserManager) (*GeoIP, error) {
+	g := &GeoIP{log: log.Named("geoip")}
+
+	var err error
+	if cfg.GeoIP.CityDB != "" {
+		if g.city, err = maxminddb.Open(cfg.GeoIP.CityDB); err != nil {
+			return nil, errors.Wrapf(err, "failed to open geoip city database %s", cfg.GeoIP.CityDB)
+		}
+		g.log.Info("opened geoip city database", zap.String("path", cfg.GeoIP.CityDB), zap.String("type", g.city.Metadata.DatabaseType))
+	}
+	if cfg.GeoIP.ASNDB != "" {
+		if g.asn, err = maxminddb.Open(cfg.GeoIP.ASNDB); err != nil {
+			g.Close()
+			return nil, errors.Wrapf(err, "failed to open geoip asn database %s", cfg.GeoIP.ASNDB)
+		}
+		g.log.Info("opened geoip asn database", zap.String("path", cfg.GeoIP.ASNDB), zap.String("type", g.asn.Metadata.DatabaseType))
+	}
+
+	cm.Register(func(ctx context.Context) error {
+		g.Close()
+		return nil
+	})
+	return g, nil
+}
+
+// HasCity reports whether the country, region and city of IPs can be looked up.
+func (g *GeoIP) HasCity() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.city != nil
+}
+
+// HasASN reports whether the autonomous system of IPs can be looked up.
+func (g *GeoIP) HasASN() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.asn != nil
+}
+
+// Lookup returns the location of an IP, ok is false if it is not a valid IP or there is no database.
+func (g *GeoIP) Lookup(ip string) (Location, bool) {
+	addr := net.ParseIP(ip)
+	if g == nil || addr == nil {
+		return Location{}, false
+	}
+	var loc Location
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.city != nil {
+		var r cityRecord
+		if err := g.city.Lookup(addr, &r); err != nil {
+			g.log.Debug("failed to look up city", zap.String("ip", ip), zap.Error(err))
+		}
+		loc.Country = r.Country.ISOCode
+		if len(r.Subdivisions) > 0 {
+			loc.Region = r.Subdivisions[0].Names["en"]
+		}
+		loc.City = r.City.Names["en"]
+	}
+	if g.asn != nil {
+		var r asnRecord
+		if err := g.asn.Lookup(addr, &r); err != nil {
+			g.log.Debug("failed to look up asn", zap.String("ip", ip), zap.Error(err))
+		}
+		loc.ASN, loc.ASOrg = r.ASN, r.ASOrg
+	}
+	return loc, true
+}
+
+func (g *GeoIP) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, db := range []**maxminddb.Reader{&g.city, &g.asn} {
+		if *db != nil {
+			_ = (*db).Close()
+			*db = nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package enrich
+
+import (
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/closer"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// The databases under testdata are the test databases of MaxMind, see https://github.com/maxmind/MaxMind-DB.
+const (
+	testCityDB = "testdata/GeoIP2-City-Test.mmdb"
+	testASNDB  = "testdata/GeoLite2-ASN-Test.mmdb"
+)
+
+func newTestGeoIP(t *testing.T, cityDB, asnDB string) *GeoIP {
+	cfg := &config.Config{}
+	cfg.GeoIP.CityDB = cityDB
+	cfg.GeoIP.ASNDB = asnDB
+	g, err := NewGeoIP(cfg, zap.NewNop(), closer.NewCloserManager(zap.NewNop()))
+	require.NoError(t, err)
+	t.Cleanup(g.Close)
+	return g
+}
+
+func TestGeoIPLookup(t *testing.T) {
+	g := newTestGeoIP(t, testCityDB, testASNDB)
+	require.True(t, g.HasCity())
+	require.True(t, g.HasASN())
+
+	testCases := []struct {
+		ip     string
+		expect Location
+	}{
+		{"81.2.69.142", Location{Country: "GB", Region: "England", City: "London"}},
+		{"89.160.20.112", Location{Country: "SE", Region: "Östergötland County", City: "Linköping", ASN: 29518, ASOrg: "Bredband2 AB"}},
+		{"216.160.83.56", Location{Country: "US", Region: "Washington", City: "Milton", ASN: 209}},
+		{"1.128.0.0", Location{ASN: 1221, ASOrg: "Telstra Pty Ltd"}},
+		{"2001:218::1", Location{Country: "JP"}},
+		// an IP missing from the databases is valid but has no location
+		{"127.0.0.1", Location{}},
+	}
+	for _, tc := range testCases {
+		loc, ok := g.Lookup(tc.ip)
+		require.True(t, ok, tc.ip)
+		require.Equal(t, tc.expect, loc, tc.ip)
+	}
+
+	_, ok := g.Lookup("not an ip")
+	require.False(t, ok)
+}
+
+func TestGeoIPSingleDatabase(t *testing.T) {
+	g := newTestGeoIP(t, "", testASNDB)
+	require.False(t, g.HasCity())
+	require.True(t, g.HasASN())
+	loc, ok := g.Lookup("89.160.20.112")
+	require.True(t, ok)
+	require.Equal(t, Location{ASN: 29518, ASOrg: "Bredband2 AB"}, loc)
+
+	// a closed GeoIP no longer locates IPs
+	g.Close()
+	require.False(t, g.HasASN())
+	loc, _ = g.Lookup("89.160.20.112")
+	require.Equal(t, Location{}, loc)
+
+	var none *GeoIP
+	require.False(t, none.HasCity())
+	_, ok = none.Lookup("89.160.20.112")
+	require.False(t, ok)
+}
+
+func TestNewGeoIPMissingDatabase(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GeoIP.CityDB = testCityDB
+	cfg.GeoIP.ASNDB = "testdata/missing.mmdb"
+	_, err := NewGeoIP(cfg, zap.NewNop(), closer.NewCloserManager(zap.NewNop()))
+	require.ErrorContains(t, err, "testdata/missing.mmdb")
+}
@@ -0,0 +1,54 @@
+package enrich
+
+import (
+	"strings"
+
+	"github.com/mssola/useragent"
+)
+
+// Device types of user agents.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// UserAgent is what a User-Agent header tells about the client. Unknown fields are empty.
+type UserAgent struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         string
+}
+
+// ParseUserAgent parses a User-Agent header, the device of a non-empty header is always known.
+func ParseUserAgent(s string) UserAgent {
+	if strings.TrimSpace(s) == "" {
+		return UserAgent{}
+	}
+	ua := useragent.New(s)
+	browser, version := ua.Browser()
+	os := ua.OSInfo()
+	return UserAgent{
+		Browser:        browser,
+		BrowserVersion: version,
+		OS:             os.Name,
+		OSVersion:      os.Version,
+		Device:         deviceType(ua, s),
+	}
+}
+
+func deviceType(ua *useragent.UserAgent, s string) string {
+	switch {
+	case ua.Bot():
+		return DeviceBot
+	case strings.Contains(s, "iPad") || strings.Contains(s, "Tablet") ||
+		strings.Contains(s, "Android") && !strings.Contains(s, "Mobile"):
+		return DeviceTablet
+	case ua.Mobile():
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
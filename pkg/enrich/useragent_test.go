@@ -0,0 +1,32 @@
+package enrich
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	testCases := []struct {
+		ua     string
+		expect UserAgent
+	}{
+		{
+			ua:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			expect: UserAgent{Browser: "Chrome", BrowserVersion: "120.0.0.0", OS: "Windows", OSVersion: "10", Device: DeviceDesktop},
+		},
+		{
+			ua:     "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			expect: UserAgent{Browser: "Safari", BrowserVersion: "17.1", OS: "iPhone OS", OSVersion: "17.1", Device: DeviceMobile},
+		},
+		{
+			ua:     "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expect: UserAgent{Browser: "Googlebot", BrowserVersion: "2.1", Device: DeviceBot},
+		},
+		{ua: "", expect: UserAgent{}},
+	}
+	for _, tc := range testCases {
+		got := ParseUserAgent(tc.ua)
+		require.Equal(t, tc.expect, got, tc.ua)
+	}
+}
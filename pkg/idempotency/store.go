@@ -0,0 +1,159 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTTL        = 24 * time.Hour
+	defaultMaxEntries = 100000
+
+	// maxKeyLength bounds the memory a single key can take.
+	maxKeyLength = 255
+)
+
+var (
+	// ErrKeyReused is returned when a key is sent again with a different request.
+	ErrKeyReused = errors.New("idempotency key reused with a different request")
+
+	// ErrInvalidKey is returned for empty or too long keys.
+	ErrInvalidKey = errors.New("invalid idempotency key")
+)
+
+var Replayed = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "events-api_idempotency_replayed",
+		Help: "The number of requests answered with the result of an earlier request with the same idempotency key",
+	},
+)
+
+// Result is the response of a completed request.
+type Result struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+type entry struct {
+	key         string
+	fingerprint string
+	expires     time.Time
+
+	// done is closed once the request completed, result is nil if it is to be retried
+	done   chan struct{}
+	result *Result
+}
+
+// Store remembers the results of the requests sent with an idempotency key, so that retries of a request get its
+// result instead of running it again. Entries expire after the TTL, the oldest ones are dropped beyond the maximum
+// number of entries.
+type Store struct {
+	ttl        time.Duration
+	maxEntries int
+	log        *zap.Logger
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+func NewStore(cfg *config.Config, log *zap.Logger) (*Store, error) {
+	s := &Store{
+		ttl:        defaultTTL,
+		maxEntries: defaultMaxEntries,
+		log:        log.Named("idempotency"),
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+	if cfg.Idempotency.TTL != "" {
+		ttl, err := time.ParseDuration(cfg.Idempotency.TTL)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid idempotency ttl")
+		}
+		if ttl <= 0 {
+			return nil, errors.Errorf("invalid idempotency ttl %s", cfg.Idempotency.TTL)
+		}
+		s.ttl = ttl
+	}
+	if cfg.Idempotency.MaxEntries > 0 {
+		s.maxEntries = cfg.Idempotency.MaxEntries
+	}
+	return s, nil
+}
+
+// Begin starts a request with an idempotency key. If an earlier request with the key completed, its result is
+// returned. If it is still running, Begin waits for it. Otherwise the caller runs the request and must call the
+// returned function with its result, or with nil if the request can be retried with the same key.
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Result, func(*Result), error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, nil, errors.Wrapf(ErrInvalidKey, "keys have 1 to %d characters", maxKeyLength)
+	}
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		if el, ok := s.entries[key]; ok {
+			e := el.Value.(*entry)
+			switch {
+			case now.After(e.expires):
+				s.remove(el)
+			case e.fingerprint != fingerprint:
+				s.mu.Unlock()
+				return nil, nil, ErrKeyReused
+			default:
+				s.mu.Unlock()
+				select {
+				case <-e.done:
+				case <-ctx.Done():
+					return nil, nil, ctx.Err()
+				}
+				if e.result != nil {
+					Replayed.Inc()
+					return e.result, nil, nil
+				}
+				// the earlier request is to be retried, it is gone from the store
+				continue
+			}
+		}
+
+		e := &entry{
+			key:         key,
+			fingerprint: fingerprint,
+			expires:     now.Add(s.ttl),
+			done:        make(chan struct{}),
+		}
+		s.entries[key] = s.lru.PushFront(e)
+		for s.lru.Len() > s.maxEntries {
+			s.remove(s.lru.Back())
+		}
+		s.mu.Unlock()
+
+		return nil, func(r *Result) { s.complete(e, r) }, nil
+	}
+}
+
+func (s *Store) complete(e *entry, r *Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.result = r
+	close(e.done)
+	if r == nil {
+		if el, ok := s.entries[e.key]; ok && el.Value.(*entry) == e {
+			s.remove(el)
+		}
+	}
+}
+
+func (s *Store) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*entry)
+	delete(s.entries, e.key)
+}
@@ -0,0 +1,65 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStore(t *testing.T) {
+	s, err := NewStore(&config.Config{Idempotency: config.Idempotency{MaxEntries: 2}}, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, done, err := s.Begin(ctx, "k1", "f1")
+	require.NoError(t, err)
+	require.Nil(t, result)
+	done(&Result{Status: 200, Body: []byte("ok")})
+
+	result, _, err = s.Begin(ctx, "k1", "f1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("ok"), result.Body)
+
+	_, _, err = s.Begin(ctx, "k1", "f2")
+	require.ErrorIs(t, err, ErrKeyReused)
+
+	// a request that can be retried is forgotten
+	_, done, err = s.Begin(ctx, "k2", "f1")
+	require.NoError(t, err)
+	done(nil)
+	result, done, err = s.Begin(ctx, "k2", "f1")
+	require.NoError(t, err)
+	require.Nil(t, result)
+	done(&Result{Status: 200})
+
+	// k1 is the oldest entry
+	_, done, err = s.Begin(ctx, "k3", "f1")
+	require.NoError(t, err)
+	done(&Result{Status: 200})
+	result, done, err = s.Begin(ctx, "k1", "f1")
+	require.NoError(t, err)
+	require.Nil(t, result)
+	done(nil)
+
+	_, _, err = s.Begin(ctx, "", "f1")
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestStoreWaitsForRunningRequest(t *testing.T) {
+	s, err := NewStore(&config.Config{}, zap.NewNop())
+	require.NoError(t, err)
+
+	_, done, err := s.Begin(context.Background(), "k", "f")
+	require.NoError(t, err)
+
+	ret := make(chan *Result)
+	go func() {
+		result, _, _ := s.Begin(context.Background(), "k", "f")
+		ret <- result
+	}()
+	done(&Result{Status: 201})
+	require.Equal(t, 201, (<-ret).Status)
+}
@@ -0,0 +1,274 @@
+package querycache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTTL           = 5 * time.Second
+	defaultMaxEntries    = 10000
+	defaultMaxBytes      = 64 * 1024 * 1024
+	defaultMaxEntryBytes = 1024 * 1024
+)
+
+var CacheHit = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "events-api_query_cache_hit",
+		Help: "The number of query results served from the cache",
+	},
+)
+
+var CacheMiss = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Name: "events-api_query_cache_miss",
+		Help: "The number of cacheable queries that were not found in the cache",
+	},
+)
+
+// Entry is a cached JSON query result.
+type Entry struct {
+	Body []byte
+	ETag string
+
+	key       string
+	relations []string
+	expires   time.Time
+}
+
+// Cache is an LRU cache of the results of read-only queries. Results expire after the TTL and are invalidated early
+// when a relation they read is written through the API or altered.
+type Cache struct {
+	enable        bool
+	ttl           time.Duration
+	maxEntries    int
+	maxBytes      int64
+	maxEntryBytes int64
+	searchPath    rw.SearchPath
+	log           *zap.Logger
+	// dependents returns the relations that depend on a relation, whose results are stale once it is written
+	dependents func(name string) []string
+
+	mu         sync.Mutex
+	lru        *list.List
+	entries    map[string]*list.Element
+	byRelation map[string]map[string]struct{}
+	bytes      int64
+}
+
+func NewCache(cfg *config.Config, searchPath rw.SearchPath, watcher *rw.Watcher, log *zap.Logger) (*Cache, error) {
+	c := &Cache{
+		enable:        cfg.QueryCache.Enable,
+		ttl:           defaultTTL,
+		maxEntries:    defaultMaxEntries,
+		maxBytes:      defaultMaxBytes,
+		maxEntryBytes: defaultMaxEntryBytes,
+		searchPath:    searchPath,
+		log:           log.Named("querycache"),
+		lru:           list.New(),
+		entries:       make(map[string]*list.Element),
+		byRelation:    make(map[string]map[string]struct{}),
+	}
+	if !c.enable {
+		return c, nil
+	}
+
+	if cfg.QueryCache.TTL != "" {
+		ttl, err := time.ParseDuration(cfg.QueryCache.TTL)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid query cache ttl")
+		}
+		c.ttl = ttl
+	}
+	if cfg.QueryCache.MaxEntries > 0 {
+		c.maxEntries = cfg.QueryCache.MaxEntries
+	}
+	if cfg.QueryCache.MaxBytes > 0 {
+		c.maxBytes = cfg.QueryCache.MaxBytes
+	}
+	if cfg.QueryCache.MaxEntryBytes > 0 {
+		c.maxEntryBytes = cfg.QueryCache.MaxEntryBytes
+	}
+
+	// the materialized views of a table change with it, so their results are dropped as well
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := watcher.TrackDependencies(ctx); err != nil {
+		c.log.Warn("failed to load dependencies, retrying on the next catalog poll", zap.Error(err))
+	}
+	c.dependents = watcher.Dependents
+	watcher.AddListener(func(relation rw.Relation) error {
+		c.Invalidate(relation.QualifiedName())
+		return nil
+	}, func(name string) error {
+		c.Invalidate(name)
+		return nil
+	})
+	return c, nil
+}
+
+func (c *Cache) Enabled() bool {
+	return c.enable
+}
+
+// Key returns the cache key of a query and the relations it may read. Only a single SELECT or VALUES statement can
+// be cached, statements that only differ in formatting share the key.
+func (c *Cache) Key(sql string, params []any) (string, []string, bool) {
+	stmts, err := statement.Split(sql)
+	if err != nil || len(stmts) != 1 {
+		return "", nil, false
+	}
+	if stmts[0].Kind != "SELECT" && stmts[0].Kind != "VALUES" {
+		return "", nil, false
+	}
+
+	key := stmts[0].Normalized()
+	if len(params) > 0 {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return "", nil, false
+		}
+		key += "\x00" + string(raw)
+	}
+	return key, c.relationsOf(&stmts[0]), true
+}
+
+// Writes returns the relations a script may write to or alter, their cached results are stale once it has run.
+func (c *Cache) Writes(sql string) []string {
+	stmts, err := statement.Split(sql)
+	if err != nil {
+		return nil
+	}
+	var ret []string
+	for i := range stmts {
+		if !stmts[i].IsReadOnly() {
+			ret = append(ret, c.relationsOf(&stmts[i])...)
+		}
+	}
+	return ret
+}
+
+// relationsOf returns the canonical names of the relations a statement may refer to, unqualified names may refer to
+// a relation in any schema of the search path.
+func (c *Cache) relationsOf(stmt *statement.Statement) []string {
+	var ret []string
+	for _, name := range stmt.Names() {
+		ret = append(ret, c.searchPath.CandidateNames(name)...)
+	}
+	return ret
+}
+
+// Get returns the cached result of the key, if it has not expired.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		CacheMiss.Inc()
+		return nil, false
+	}
+	e := el.Value.(*Entry)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		CacheMiss.Inc()
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	CacheHit.Inc()
+	return e, true
+}
+
+// Put caches the result of the key and returns it as an entry. Results larger than the entry size limit are returned
+// without being cached.
+func (c *Cache) Put(key string, relations []string, body []byte) *Entry {
+	e := &Entry{
+		Body:      body,
+		ETag:      ETag(body),
+		key:       key,
+		relations: relations,
+		expires:   time.Now().Add(c.ttl),
+	}
+	if int64(len(body)) > c.maxEntryBytes {
+		return e
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.bytes += int64(len(body))
+	for _, r := range relations {
+		keys, ok := c.byRelation[r]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.byRelation[r] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.lru.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+	return e
+}
+
+// Invalidate drops the cached results that read the relation or a relation that depends on it, such as the
+// materialized views of a table. Unqualified names drop the results of the relations of that name in every schema of
+// the search path.
+func (c *Cache) Invalidate(relation string) {
+	if !c.enable {
+		return
+	}
+	names := c.searchPath.CandidateNames(relation)
+	if c.dependents != nil {
+		names = append(names, c.dependents(relation)...)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range names {
+		for key := range c.byRelation[name] {
+			if el, ok := c.entries[key]; ok {
+				c.remove(el)
+			}
+		}
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*Entry)
+	delete(c.entries, e.key)
+	c.bytes -= int64(len(e.Body))
+	for _, r := range e.relations {
+		if keys, ok := c.byRelation[r]; ok {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(c.byRelation, r)
+			}
+		}
+	}
+}
+
+// ETag returns a strong entity tag of a response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
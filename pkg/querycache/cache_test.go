@@ -0,0 +1,109 @@
+package querycache
+
+import (
+	"container/list"
+	"testing"
+	"time"
+
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestCache(maxEntries int, maxBytes int64) *Cache {
+	return &Cache{
+		enable:        true,
+		ttl:           time.Minute,
+		maxEntries:    maxEntries,
+		maxBytes:      maxBytes,
+		maxEntryBytes: 100,
+		searchPath:    rw.SearchPath{"public"},
+		log:           zap.NewNop(),
+		lru:           list.New(),
+		entries:       make(map[string]*list.Element),
+		byRelation:    make(map[string]map[string]struct{}),
+	}
+}
+
+func TestKey(t *testing.T) {
+	c := newTestCache(10, 1000)
+	k1, relations, ok := c.Key("SELECT * FROM page_views_mv WHERE views > $1", []any{int64(10)})
+	require.True(t, ok)
+	require.Contains(t, relations, "public.page_views_mv")
+
+	k2, _, ok := c.Key("select *\n from PAGE_VIEWS_MV -- top pages\n where views > $1;", []any{int64(10)})
+	require.True(t, ok)
+	require.Equal(t, k1, k2)
+
+	k3, _, ok := c.Key("SELECT * FROM page_views_mv WHERE views > $1", []any{int64(20)})
+	require.True(t, ok)
+	require.NotEqual(t, k1, k3)
+
+	for _, sql := range []string{"SHOW TABLES", "INSERT INTO t VALUES (1)", "SELECT 1; SELECT 2"} {
+		_, _, ok := c.Key(sql, nil)
+		require.False(t, ok, sql)
+	}
+
+	require.Contains(t, c.Writes("SELECT 1; DROP MATERIALIZED VIEW analytics.top_pages"), "analytics.top_pages")
+	require.Empty(t, c.Writes("SELECT * FROM t"))
+}
+
+func TestCache(t *testing.T) {
+	c := newTestCache(2, 1000)
+
+	e := c.Put("a", []string{"public.t"}, []byte(`{"rows":[]}`))
+	require.Equal(t, ETag([]byte(`{"rows":[]}`)), e.ETag)
+	got, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, e, got)
+
+	c.Invalidate("t")
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Empty(t, c.byRelation)
+
+	// least recently used entries are evicted first
+	c.Put("a", nil, []byte("1"))
+	c.Put("b", nil, []byte("2"))
+	c.Get("a")
+	c.Put("c", nil, []byte("3"))
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	_, ok = c.Get("a")
+	require.True(t, ok)
+
+	// results above the entry limit are not cached
+	c.Put("big", nil, make([]byte, 101))
+	_, ok = c.Get("big")
+	require.False(t, ok)
+
+	// expired results are dropped
+	c.ttl = -time.Second
+	c.Put("d", nil, []byte("4"))
+	_, ok = c.Get("d")
+	require.False(t, ok)
+	require.Equal(t, int64(1), c.bytes)
+}
+
+func TestCacheInvalidateDependents(t *testing.T) {
+	c := newTestCache(10, 1000)
+	c.dependents = func(name string) []string {
+		if name == "clicks" {
+			return []string{"public.clicks_by_page", "analytics.top_pages"}
+		}
+		return nil
+	}
+
+	c.Put("mv", []string{"public.clicks_by_page"}, []byte("1"))
+	c.Put("nested", []string{"analytics.top_pages"}, []byte("2"))
+	c.Put("other", []string{"public.users"}, []byte("3"))
+
+	// ingesting into the table drops the results of its materialized views
+	c.Invalidate("clicks")
+	_, ok := c.Get("mv")
+	require.False(t, ok)
+	_, ok = c.Get("nested")
+	require.False(t, ok)
+	_, ok = c.Get("other")
+	require.True(t, ok)
+}
@@ -0,0 +1,448 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/closer"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"go.uber.org/zap"
+)
+
+const (
+	KeyByAPIKey = "apikey"
+	KeyByIP     = "ip"
+	KeyByTable  = "table"
+
+	DimRequests = "requests"
+	DimEvents   = "events"
+	DimBytes    = "bytes"
+
+	AnonymousKey = "anonymous"
+
+	bucketIdleTTL     = 5 * time.Minute
+	maxUsageEntries   = 100_000
+	janitorInterval   = 1 * time.Minute
+	quotaSaveInterval = 10 * time.Second
+)
+
+// Request describes the cost of a single HTTP request.
+type Request struct {
+	APIKey string
+	IP     string
+	Table  string
+
+	Events int64
+	Bytes  int64
+}
+
+// Status is the state of the tightest bucket or quota of one dimension after a decision.
+type Status struct {
+	Dimension string
+	Limit     float64
+	Remaining float64
+}
+
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration
+	// Reason describes the limit that rejected the request.
+	Reason string
+
+	Rates  []Status
+	Quotas []Status
+}
+
+type bucket struct {
+	tokens   float64
+	rate     float64
+	capacity float64
+	last     time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// allows reports whether n tokens can be taken. A cost larger than the bucket capacity is allowed
+// once the bucket is full, leaving the bucket in debt, so that big batches are throttled instead of
+// being rejected forever.
+func (b *bucket) allows(n float64) (bool, time.Duration) {
+	need := math.Min(n, b.capacity)
+	if b.tokens >= need {
+		return true, 0
+	}
+	return false, time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+type take struct {
+	b   *bucket
+	n   float64
+	dim string
+}
+
+type quotaCheck struct {
+	u     *Usage
+	dim   string
+	limit int64
+}
+
+type bucketKey struct {
+	rule  int
+	dim   string
+	value string
+}
+
+type usageKey struct {
+	by    string
+	value string
+}
+
+type Usage struct {
+	Requests int64
+	Events   int64
+	Bytes    int64
+
+	// last is when the usage was last checked, zero for usage loaded from the store
+	last time.Time
+}
+
+func (u *Usage) get(dim string) int64 {
+	switch dim {
+	case DimRequests:
+		return u.Requests
+	case DimEvents:
+		return u.Events
+	case DimBytes:
+		return u.Bytes
+	}
+	return 0
+}
+
+type rule struct {
+	config.RateLimitRule
+	idx int
+}
+
+func (r *rule) value(req *Request) (string, bool) {
+	var v string
+	switch r.By {
+	case KeyByAPIKey:
+		v = req.APIKey
+		if v == "" {
+			v = AnonymousKey
+		}
+	case KeyByIP:
+		v = req.IP
+	case KeyByTable:
+		v = req.Table
+	}
+	if v == "" {
+		return "", false
+	}
+	if r.Match != "" && r.Match != v {
+		return "", false
+	}
+	return v, true
+}
+
+type Limiter struct {
+	rules []rule
+	store QuotaStore
+	log   *zap.Logger
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	day     string
+	usage   map[usageKey]*Usage
+	dirty   bool
+	// maxUsage caps the number of usage entries, see evict
+	maxUsage int
+
+	now func() time.Time
+}
+
+func NewLimiter(cfg *config.Config, globalCtx *gctx.GlobalContext, rwc *rw.RisingWave, cm *closer.CloserManager, log *zap.Logger) (*Limiter, error) {
+	l := &Limiter{
+		log:      log.Named("ratelimit"),
+		buckets:  make(map[bucketKey]*bucket),
+		usage:    make(map[usageKey]*Usage),
+		maxUsage: maxUsageEntries,
+		now:      time.Now,
+	}
+
+	if !cfg.RateLimit.Enable {
+		return l, nil
+	}
+
+	for i, r := range cfg.RateLimit.Rules {
+		switch r.By {
+		case KeyByAPIKey, KeyByIP, KeyByTable:
+		default:
+			return nil, errors.Errorf("invalid rate limit rule %d: unknown key %q", i, r.By)
+		}
+		if r.Burst <= 0 {
+			r.Burst = 1
+		}
+		l.rules = append(l.rules, rule{RateLimitRule: r, idx: i})
+	}
+
+	l.day = dayOf(l.now())
+
+	if cfg.RateLimit.PersistTable != "" {
+		store, err := newRisingWaveStore(globalCtx.Context(), rwc, cfg.RateLimit.PersistTable)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create quota store")
+		}
+		l.store = store
+
+		usage, err := store.Load(globalCtx.Context(), l.day)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load quota usage")
+		}
+		for k, u := range usage {
+			l.usage[k] = u
+		}
+
+		cm.Register(func(ctx context.Context) error {
+			return l.save(ctx)
+		})
+	}
+
+	go l.janitor(globalCtx.Context())
+
+	return l, nil
+}
+
+func (l *Limiter) Enabled() bool {
+	return len(l.rules) > 0
+}
+
+// Allow checks the request against every matching rule and consumes tokens and quota only if all of them allow it.
+func (l *Limiter) Allow(req Request) Decision {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if day := dayOf(now); day != l.day {
+		l.day = day
+		l.usage = make(map[usageKey]*Usage)
+	}
+
+	cost := map[string]float64{
+		DimRequests: 1,
+		DimEvents:   float64(req.Events),
+		DimBytes:    float64(req.Bytes),
+	}
+
+	var (
+		decision = Decision{Allowed: true}
+		taken    []take
+		checked  []quotaCheck
+		used     = map[usageKey]*Usage{}
+	)
+
+	for i := range l.rules {
+		r := &l.rules[i]
+		v, ok := r.value(&req)
+		if !ok {
+			continue
+		}
+
+		for dim, rate := range map[string]float64{
+			DimRequests: r.RequestsPerSec,
+			DimEvents:   r.EventsPerSec,
+			DimBytes:    r.BytesPerSec,
+		} {
+			if rate <= 0 {
+				continue
+			}
+			key := bucketKey{rule: r.idx, dim: dim, value: v}
+			b, ok := l.buckets[key]
+			if !ok {
+				b = &bucket{tokens: rate * r.Burst, rate: rate, capacity: rate * r.Burst, last: now}
+				l.buckets[key] = b
+			}
+			b.refill(now)
+
+			if allowed, wait := b.allows(cost[dim]); !allowed {
+				decision.Allowed = false
+				if wait > decision.RetryAfter {
+					decision.RetryAfter = wait
+					decision.Reason = "rate limit of " + dim + " per second exceeded for " + r.By + " " + v
+				}
+			}
+			taken = append(taken, take{b: b, n: cost[dim], dim: dim})
+		}
+
+		if r.DailyRequests <= 0 && r.DailyEvents <= 0 && r.DailyBytes <= 0 {
+			continue
+		}
+
+		uk := usageKey{by: r.By, value: v}
+		u, ok := l.usage[uk]
+		if !ok {
+			u = &Usage{}
+			l.usage[uk] = u
+		}
+		u.last = now
+		used[uk] = u
+
+		for dim, limit := range map[string]int64{
+			DimRequests: r.DailyRequests,
+			DimEvents:   r.DailyEvents,
+			DimBytes:    r.DailyBytes,
+		} {
+			if limit <= 0 {
+				continue
+			}
+			if u.get(dim)+int64(cost[dim]) > limit {
+				decision.Allowed = false
+				if wait := tomorrow(now).Sub(now); wait > decision.RetryAfter {
+					decision.RetryAfter = wait
+					decision.Reason = "daily quota of " + dim + " exceeded for " + r.By + " " + v
+				}
+			}
+			checked = append(checked, quotaCheck{u: u, dim: dim, limit: limit})
+		}
+	}
+
+	if decision.Allowed {
+		for _, t := range taken {
+			t.b.tokens -= t.n
+		}
+		for _, u := range used {
+			u.Requests++
+			u.Events += req.Events
+			u.Bytes += req.Bytes
+		}
+		if len(used) > 0 {
+			l.dirty = true
+		}
+	}
+
+	rates := map[string]Status{}
+	for _, t := range taken {
+		mergeStatus(rates, Status{Dimension: t.dim, Limit: t.b.rate, Remaining: math.Max(0, t.b.tokens)})
+	}
+	quotas := map[string]Status{}
+	for _, q := range checked {
+		mergeStatus(quotas, Status{Dimension: q.dim, Limit: float64(q.limit), Remaining: math.Max(0, float64(q.limit-q.u.get(q.dim)))})
+	}
+	for _, s := range rates {
+		decision.Rates = append(decision.Rates, s)
+	}
+	for _, s := range quotas {
+		decision.Quotas = append(decision.Quotas, s)
+	}
+
+	return decision
+}
+
+// mergeStatus keeps the status with the least remaining tokens per dimension.
+func mergeStatus(m map[string]Status, s Status) {
+	if cur, ok := m[s.Dimension]; ok && cur.Remaining <= s.Remaining {
+		return
+	}
+	m[s.Dimension] = s
+}
+
+func (l *Limiter) janitor(ctx context.Context) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	var saveTicker <-chan time.Time
+	if l.store != nil {
+		t := time.NewTicker(quotaSaveInterval)
+		defer t.Stop()
+		saveTicker = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.evict(l.now())
+		case <-saveTicker:
+			c, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := l.save(c); err != nil {
+				l.log.Error("failed to save quota usage", zap.Error(err))
+			}
+			cancel()
+		}
+	}
+}
+
+// evict drops the idle buckets and, if there are more usage entries than maxUsage, the least recently used ones.
+// Usage entries are not dropped merely for being idle since that would reset their daily quota, but keys such as
+// client IPs must not grow the usage without bound until the day ends.
+func (l *Limiter) evict(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, k)
+		}
+	}
+
+	if len(l.usage) <= l.maxUsage {
+		return
+	}
+	keys := make([]usageKey, 0, len(l.usage))
+	for k := range l.usage {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return l.usage[keys[i]].last.Before(l.usage[keys[j]].last) })
+	evicted := keys[:len(keys)-l.maxUsage]
+	for _, k := range evicted {
+		delete(l.usage, k)
+	}
+	l.log.Warn("too many quota usage entries, forgot the least recently used ones",
+		zap.Int("evicted", len(evicted)), zap.Int("max", l.maxUsage))
+}
+
+func (l *Limiter) save(ctx context.Context) error {
+	if l.store == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	if !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	day := l.day
+	snapshot := make(map[usageKey]Usage, len(l.usage))
+	for k, u := range l.usage {
+		snapshot[k] = *u
+	}
+	l.dirty = false
+	l.mu.Unlock()
+
+	if err := l.store.Save(ctx, day, snapshot); err != nil {
+		l.mu.Lock()
+		l.dirty = true
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+func tomorrow(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
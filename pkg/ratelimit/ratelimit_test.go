@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestLimiter(now *time.Time, rules ...config.RateLimitRule) *Limiter {
+	l := &Limiter{
+		log:      zap.NewNop(),
+		buckets:  make(map[bucketKey]*bucket),
+		usage:    make(map[usageKey]*Usage),
+		maxUsage: maxUsageEntries,
+		now:      func() time.Time { return *now },
+	}
+	for i, r := range rules {
+		if r.Burst <= 0 {
+			r.Burst = 1
+		}
+		l.rules = append(l.rules, rule{RateLimitRule: r, idx: i})
+	}
+	l.day = dayOf(*now)
+	return l
+}
+
+func TestLimiterEventsPerTable(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.RateLimitRule{By: KeyByTable, EventsPerSec: 100})
+
+	require.True(t, l.Allow(Request{Table: "public.a", Events: 60}).Allowed)
+	require.True(t, l.Allow(Request{Table: "public.a", Events: 40}).Allowed)
+
+	d := l.Allow(Request{Table: "public.a", Events: 10})
+	require.False(t, d.Allowed)
+	require.Equal(t, 100*time.Millisecond, d.RetryAfter)
+
+	// other tables have their own bucket
+	require.True(t, l.Allow(Request{Table: "public.b", Events: 100}).Allowed)
+
+	now = now.Add(100 * time.Millisecond)
+	require.True(t, l.Allow(Request{Table: "public.a", Events: 10}).Allowed)
+}
+
+func TestLimiterOversizedRequestGoesIntoDebt(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.RateLimitRule{By: KeyByIP, BytesPerSec: 1000})
+
+	require.True(t, l.Allow(Request{IP: "10.0.0.1", Bytes: 5000}).Allowed)
+	require.False(t, l.Allow(Request{IP: "10.0.0.1", Bytes: 1}).Allowed)
+
+	now = now.Add(5 * time.Second)
+	require.True(t, l.Allow(Request{IP: "10.0.0.1", Bytes: 1}).Allowed)
+}
+
+func TestLimiterRejectedRequestConsumesNothing(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now,
+		config.RateLimitRule{By: KeyByAPIKey, RequestsPerSec: 10},
+		config.RateLimitRule{By: KeyByAPIKey, DailyRequests: 1},
+	)
+
+	require.True(t, l.Allow(Request{APIKey: "k"}).Allowed)
+
+	d := l.Allow(Request{APIKey: "k"})
+	require.False(t, d.Allowed)
+	require.Contains(t, d.Reason, "daily quota")
+
+	// the requests bucket still has 9 tokens left since the rejected request was not charged
+	require.Equal(t, []Status{{Dimension: DimRequests, Limit: 10, Remaining: 9}}, d.Rates)
+	require.Equal(t, []Status{{Dimension: DimRequests, Limit: 1, Remaining: 0}}, d.Quotas)
+
+	now = now.Add(24 * time.Hour)
+	require.True(t, l.Allow(Request{APIKey: "k"}).Allowed)
+}
+
+func TestLimiterEvictsUsage(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(&now, config.RateLimitRule{By: KeyByIP, RequestsPerSec: 10, DailyRequests: 2})
+	l.maxUsage = 2
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		require.True(t, l.Allow(Request{IP: ip}).Allowed)
+		now = now.Add(time.Second)
+	}
+	require.True(t, l.Allow(Request{IP: "10.0.0.1"}).Allowed)
+
+	// idle usage is only forgotten beyond the cap, least recently used first: 10.0.0.2 starts over
+	now = now.Add(time.Hour)
+	l.evict(now)
+	require.Empty(t, l.buckets)
+	require.Len(t, l.usage, 2)
+	require.False(t, l.Allow(Request{IP: "10.0.0.1"}).Allowed)
+	require.True(t, l.Allow(Request{IP: "10.0.0.3"}).Allowed)
+	require.True(t, l.Allow(Request{IP: "10.0.0.2"}).Allowed)
+}
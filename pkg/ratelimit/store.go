@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+)
+
+// QuotaStore persists the daily quota usage so that quotas survive restarts.
+type QuotaStore interface {
+	Load(ctx context.Context, day string) (map[usageKey]*Usage, error)
+	Save(ctx context.Context, day string, usage map[usageKey]Usage) error
+}
+
+const quotaRowsPerStatement = 1000
+
+type risingWaveStore struct {
+	rw    *rw.RisingWave
+	table string
+}
+
+func newRisingWaveStore(ctx context.Context, rwc *rw.RisingWave, table string) (*risingWaveStore, error) {
+	ident, err := rw.Identifier(table)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid rate limit persist table")
+	}
+	s := &risingWaveStore{
+		rw:    rwc,
+		table: ident,
+	}
+
+	if _, err := rwc.Pool().Exec(ctx, `CREATE TABLE IF NOT EXISTS `+s.table+` (
+		day       VARCHAR,
+		key_by    VARCHAR,
+		key_value VARCHAR,
+		requests  BIGINT,
+		events    BIGINT,
+		bytes     BIGINT,
+		PRIMARY KEY (day, key_by, key_value)
+	)`); err != nil {
+		return nil, errors.Wrapf(err, "failed to create quota table %s", table)
+	}
+
+	return s, nil
+}
+
+func (s *risingWaveStore) Load(ctx context.Context, day string) (map[usageKey]*Usage, error) {
+	rows, err := s.rw.Pool().Query(ctx, `SELECT key_by, key_value, requests, events, bytes FROM `+s.table+` WHERE day = $1`, day)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query quota usage")
+	}
+	defer rows.Close()
+
+	ret := make(map[usageKey]*Usage)
+	for rows.Next() {
+		var (
+			k usageKey
+			u Usage
+		)
+		if err := rows.Scan(&k.by, &k.value, &u.Requests, &u.Events, &u.Bytes); err != nil {
+			return nil, errors.Wrap(err, "failed to scan quota usage")
+		}
+		ret[k] = &u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "error occurred during rows iteration")
+	}
+	return ret, nil
+}
+
+// Save overwrites the usage of the day, relying on the primary key of the table to upsert rows.
+func (s *risingWaveStore) Save(ctx context.Context, day string, usage map[usageKey]Usage) error {
+	var (
+		sb   strings.Builder
+		args []any
+		n    int
+	)
+
+	exec := func() error {
+		if n == 0 {
+			return nil
+		}
+		sb.WriteString("; FLUSH;")
+		if _, err := s.rw.Pool().Exec(ctx, sb.String(), args...); err != nil {
+			return errors.Wrap(err, "failed to save quota usage")
+		}
+		sb.Reset()
+		args = args[:0]
+		n = 0
+		return nil
+	}
+
+	for k, u := range usage {
+		if n == 0 {
+			sb.WriteString("INSERT INTO " + s.table + " (day, key_by, key_value, requests, events, bytes) VALUES ")
+		} else {
+			sb.WriteString(", ")
+		}
+		p := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d)", p+1, p+2, p+3, p+4, p+5, p+6)
+		args = append(args, day, k.by, k.value, u.Requests, u.Events, u.Bytes)
+		n++
+
+		if n >= quotaRowsPerStatement {
+			if err := exec(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return exec()
+}
@@ -0,0 +1,92 @@
+package rw
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+)
+
+// BatchStatement is a statement of a batch and its parameters.
+type BatchStatement struct {
+	SQL    string
+	Params []any
+}
+
+// BatchError is the error of the statement of a batch at Index, starting from 0.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("statement %d: %v", e.Index+1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteBatch runs the statements in order on a single connection and stops at the first one that fails, which is
+// reported as a *BatchError along with the results of the statements before it. In a transaction, the statements run
+// between BEGIN and COMMIT and are rolled back on failure. RisingWave only supports read-only transactions, so a
+// transaction with a statement that writes is rejected before any statement runs.
+func (rw *RisingWave) ExecuteBatch(ctx context.Context, stmts []BatchStatement, transaction bool) ([]apigen.QueryResponse, error) {
+	if transaction {
+		if err := checkReadOnly(stmts); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := rw.pool.Acquire(ctx)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	defer conn.Release()
+
+	var db DB = conn
+	if transaction {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return nil, queryError(err)
+		}
+		// rolling back a committed transaction is a no-op
+		defer func() { _ = tx.Rollback(context.Background()) }()
+		db = tx
+	}
+
+	ret := make([]apigen.QueryResponse, 0, len(stmts))
+	for i, stmt := range stmts {
+		result, err := query(ctx, db, stmt.SQL, false, stmt.Params...)
+		if err != nil {
+			return ret, &BatchError{Index: i, Err: err}
+		}
+		ret = append(ret, *result.response())
+	}
+
+	if tx, ok := db.(pgx.Tx); ok {
+		if err := tx.Commit(ctx); err != nil {
+			return ret, errors.Wrap(queryError(err), "failed to commit")
+		}
+	}
+	return ret, nil
+}
+
+// checkReadOnly returns a *BatchError wrapping ErrReadOnlyTransaction for the first statement that is not read-only.
+func checkReadOnly(stmts []BatchStatement) error {
+	for i, stmt := range stmts {
+		parsed, err := statement.Split(stmt.SQL)
+		if err != nil {
+			return &BatchError{Index: i, Err: errors.Wrap(ErrReadOnlyTransaction, err.Error())}
+		}
+		for _, p := range parsed {
+			if !p.IsReadOnly() {
+				return &BatchError{Index: i, Err: errors.Wrapf(ErrReadOnlyTransaction, "%s is not read-only", p.Kind)}
+			}
+		}
+	}
+	return nil
+}
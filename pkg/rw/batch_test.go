@@ -0,0 +1,33 @@
+package rw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteBatchReadOnlyTransaction(t *testing.T) {
+	stmts := []BatchStatement{
+		{SQL: "SELECT 1"},
+		{SQL: "SHOW TABLES; SELECT * FROM t"},
+		{SQL: "INSERT INTO t VALUES (1)"},
+		{SQL: "CREATE TABLE u (id INT)"},
+	}
+
+	// the batch is rejected before a connection is acquired
+	rw := &RisingWave{}
+	results, err := rw.ExecuteBatch(context.Background(), stmts, true)
+	require.Empty(t, results)
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 2, batchErr.Index)
+	require.ErrorIs(t, err, ErrReadOnlyTransaction)
+	require.ErrorContains(t, err, "statement 3: INSERT is not read-only")
+
+	require.NoError(t, checkReadOnly(stmts[:2]))
+
+	err = checkReadOnly([]BatchStatement{{SQL: "SELECT 'unterminated"}})
+	require.True(t, errors.Is(err, ErrReadOnlyTransaction))
+}
@@ -2,12 +2,13 @@ package rw
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -80,14 +81,18 @@ var BulkInsertBackpressureHit = promauto.NewCounter(
 
 type Item struct {
 	rows [][]any
-	c    chan error
+	// ops are the operations of the rows, nil if they are all inserts
+	ops []EventOp
+	c   chan error
 }
 
 type BulkInsertOperator struct {
-	log   *zap.Logger
-	sql   string
-	table string
-	cols  []Column
+	log       *zap.Logger
+	sql       string
+	deleteSQL string
+	table     string
+	cols      []Column
+	pk        []int
 
 	itemPool sync.Pool
 
@@ -102,20 +107,42 @@ type BulkInsertOperator struct {
 	closed    *atomic.Bool
 	runCancel context.CancelFunc
 	inFlight  *atomic.Int32
+
+	// drainC asks the run goroutine to drain, flushes tracks the flushes that are running
+	drainC    chan struct{}
+	drainOnce sync.Once
+	flushes   sync.WaitGroup
+
+	// once the operator has seen a delete, flushes run one after the other so that deletes and inserts of the same
+	// key apply in order. lastDone is closed when the last flush and all flushes before it are done.
+	ordered  bool
+	lastDone chan struct{}
 }
 
 func newBulkInsertOperator(ctx context.Context, table string, cols []Column, conn Connection, bufSize int, log *zap.Logger) *BulkInsertOperator {
 	ctx, cancel := context.WithCancel(ctx)
 
+	var pk []int
+	for i, c := range cols {
+		if c.IsPrimaryKey {
+			pk = append(pk, i)
+		}
+	}
+	lastDone := make(chan struct{})
+	close(lastDone)
+
 	o := &BulkInsertOperator{
-		sql:     _buildPrepareSQL(table, cols),
-		cols:    cols,
-		buf:     make([]*Item, 0, bufSize),
-		conn:    conn,
-		c:       make(chan *Item, bufSize),
-		bufSize: bufSize,
-		maxRows: MaxParamLimit / len(cols),
-		table:   table,
+		sql:       _buildPrepareSQL(table, cols),
+		deleteSQL: "DELETE FROM " + table + " WHERE ",
+		cols:      cols,
+		pk:        pk,
+		lastDone:  lastDone,
+		buf:       make([]*Item, 0, bufSize),
+		conn:      conn,
+		c:         make(chan *Item, bufSize),
+		bufSize:   bufSize,
+		maxRows:   MaxParamLimit / len(cols),
+		table:     table,
 		log: log.Named("bulk_insert").With(
 			zap.String("table", table),
 		),
@@ -129,6 +156,7 @@ func newBulkInsertOperator(ctx context.Context, table string, cols []Column, con
 		runCancel: cancel,
 		closed:    &atomic.Bool{},
 		inFlight:  &atomic.Int32{},
+		drainC:    make(chan struct{}),
 	}
 
 	o.run(ctx)
@@ -136,16 +164,27 @@ func newBulkInsertOperator(ctx context.Context, table string, cols []Column, con
 	return o
 }
 
+// Close stops the operator right away, buffered and new inserts fail with ErrBulkInsertClosed.
 func (o *BulkInsertOperator) Close() {
 	o.runCancel()
 }
 
+// Drain stops the operator gracefully. New inserts fail with ErrBulkInsertClosed, while the inserts already accepted
+// and those in flight are still flushed with the columns of the operator. It returns right away, the operator stops
+// once the last flush is done.
+func (o *BulkInsertOperator) Drain() {
+	o.drainOnce.Do(func() { close(o.drainC) })
+}
+
 func (o *BulkInsertOperator) releaseItem(item *Item) {
 	item.rows = nil
+	item.ops = nil
 	o.itemPool.Put(item)
 }
 
-func (o *BulkInsertOperator) Insert(ctx context.Context, rows [][]any) error {
+// Insert writes the rows with the next flush and waits for it. ops are the operations of the rows, nil inserts all of
+// them. Deleted rows only need the values of the primary key columns.
+func (o *BulkInsertOperator) Insert(ctx context.Context, rows [][]any, ops []EventOp) error {
 	o.inFlight.Add(1)
 	defer o.inFlight.Add(-1)
 
@@ -156,6 +195,7 @@ func (o *BulkInsertOperator) Insert(ctx context.Context, rows [][]any) error {
 	item := o.itemPool.Get().(*Item)
 
 	item.rows = rows
+	item.ops = ops
 
 	select {
 	case <-item.c: // drain previous error if any
@@ -196,28 +236,11 @@ func (o *BulkInsertOperator) run(ctx context.Context) {
 				// block all new inserts
 				o.closed.Store(true)
 
-				// flush remaining buffer
-				if len(o.buf) > 0 {
-					o.onFlushDone(ErrBulkInsertClosed, o.buf)
-				}
-
-				// keep draining the channel until all in-flight inserts are done
-				for {
-					select {
-					case <-tick.C:
-						if o.inFlight.Load() == 0 {
-							close(o.c)
-							for item := range o.c {
-								item.c <- ErrBulkInsertClosed
-							}
-							return
-						}
-					case item, ok := <-o.c:
-						if ok {
-							item.c <- ErrBulkInsertClosed
-						}
-					}
-				}
+				o.reject(tick)
+				return
+			case <-o.drainC:
+				o.drain(ctx, tick)
+				return
 			case <-tick.C:
 				if len(o.buf) > 0 {
 					BulkInsertFlushByTimeout.Add(1)
@@ -230,39 +253,119 @@ func (o *BulkInsertOperator) run(ctx context.Context) {
 					}
 					return
 				}
-				o.buf = append(o.buf, args)
-				o.rowCnt += len(args.rows)
-				if o.rowCnt >= o.maxRows || len(o.buf) >= o.bufSize {
-					BulkInsertFlushBySize.Add(1)
-					o.flush(ctx)
-				}
+				o.add(ctx, args)
 			}
 		}
 	}()
 }
 
+// drain flushes the inserts that are accepted or in flight once new inserts are rejected, then waits for the flushes
+// before releasing the context of the operator. It runs in the run goroutine.
+func (o *BulkInsertOperator) drain(ctx context.Context, tick *time.Ticker) {
+	o.closed.Store(true)
+	for {
+		select {
+		case <-ctx.Done():
+			o.reject(tick)
+			return
+		case <-tick.C:
+			o.flush(ctx)
+			// inserts in flight are either waiting for the result of a flush or about to send their item
+			if o.inFlight.Load() == 0 && len(o.c) == 0 {
+				go func() {
+					o.flushes.Wait()
+					o.runCancel()
+				}()
+				return
+			}
+		case item := <-o.c:
+			o.add(ctx, item)
+		}
+	}
+}
+
+// reject fails the buffered rows and the inserts that are in flight with ErrBulkInsertClosed once the operator is
+// closed. An insert that passed the closed check may still send its item, so the channel is only closed once no
+// insert is in flight. It runs in the run goroutine.
+func (o *BulkInsertOperator) reject(tick *time.Ticker) {
+	if len(o.buf) > 0 {
+		o.onFlushDone(ErrBulkInsertClosed, o.buf)
+	}
+	for {
+		select {
+		case <-tick.C:
+			if o.inFlight.Load() == 0 {
+				close(o.c)
+				for item := range o.c {
+					item.c <- ErrBulkInsertClosed
+				}
+				return
+			}
+		case item, ok := <-o.c:
+			if ok {
+				item.c <- ErrBulkInsertClosed
+			}
+		}
+	}
+}
+
+// add buffers an item and flushes the buffer once it is full. It should only be called in the run goroutine.
+func (o *BulkInsertOperator) add(ctx context.Context, item *Item) {
+	if !o.ordered {
+		for _, op := range item.ops {
+			if op == EventDelete {
+				o.ordered = true
+				break
+			}
+		}
+	}
+	o.buf = append(o.buf, item)
+	o.rowCnt += len(item.rows)
+	if o.rowCnt >= o.maxRows || len(o.buf) >= o.bufSize {
+		BulkInsertFlushBySize.Add(1)
+		o.flush(ctx)
+	}
+}
+
 // flush is not thread-safe. It should only be called in the run goroutine.
 func (o *BulkInsertOperator) flush(ctx context.Context) {
 	if len(o.buf) == 0 {
 		return
 	}
-	sql, args := _buildInsertStatement(o.sql, o.buf, o.cols)
+	sql, args := _buildStatements(o.sql, o.deleteSQL, o.buf, o.cols, o.pk)
 	items := make([]*Item, len(o.buf))
 	copy(items, o.buf)
 	o.buf = o.buf[:0]
 	o.rowCnt = 0
+
+	var (
+		ordered = o.ordered
+		prev    = o.lastDone
+		done    = make(chan struct{})
+	)
+	o.lastDone = done
+	o.flushes.Add(1)
 	go func() {
+		defer o.flushes.Done()
 		FlushGoroutine.Inc()
 		defer FlushGoroutine.Dec()
 
+		if ordered {
+			<-prev
+		}
+
 		c, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
 		var err error
 		if _, err = o.conn.Exec(c, sql, args...); err != nil {
 			o.log.Error("failed to run exec in bulk insert operator", zap.Error(err), zap.String("table", o.table), zap.Int("n_args", len(args)))
+			err = queryError(err)
 		}
 		o.onFlushDone(err, items)
+
+		<-prev
+		close(done)
 	}()
 }
 
@@ -278,51 +381,76 @@ func (o *BulkInsertOperator) onFlushDone(err error, items []*Item) {
 	o.log.Debug("bulk insert flush done", zap.Int("n_items", len(items)), zap.Error(err))
 }
 
+// _buildPrepareSQL returns the head of the insert statement, table is the quoted identifier of the table.
 func _buildPrepareSQL(table string, cols []Column) string {
 	names := make([]string, 0, len(cols))
 	for _, c := range cols {
-		names = append(names, c.Name)
+		names = append(names, pgx.Identifier{c.Name}.Sanitize())
 	}
 	return "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES "
 }
 
-func _buildInsertStatement(sql string, items []*Item, cols []Column) (string, []any) {
-	n := 0
-	for _, item := range items {
-		n += len(item.rows) * len(cols)
+// _buildStatements returns the statements writing the rows of the items followed by FLUSH. Consecutive inserts and
+// upserts are written by one INSERT and consecutive deletes by one DELETE, so that the rows apply in order. An upsert
+// writes every column and thus replaces the row with the same key, the columns missing from its event are NULL. pk
+// are the indexes of the primary key columns.
+func _buildStatements(insertSQL, deleteSQL string, items []*Item, cols []Column, pk []int) (string, []any) {
+	var (
+		sb   strings.Builder
+		args []any
+		run  = -1 // whether the statement being written is an INSERT (0) or a DELETE (1)
+	)
+	param := func(v any) {
+		args = append(args, v)
+		sb.WriteString("$")
+		sb.WriteString(strconv.Itoa(len(args)))
 	}
-	var pos int = 0
-	var sb strings.Builder
-	sb.WriteString(sql)
-	for i, item := range items {
-		for j := range item.rows {
-			sb.WriteString("(")
-			for k := range cols {
-				sb.WriteString("$")
-				sb.WriteString(fmt.Sprint(pos + 1))
-				pos++
-				if k != len(cols)-1 {
-					sb.WriteString(", ")
+	for _, item := range items {
+		for j, row := range item.rows {
+			if item.ops != nil && item.ops[j] == EventDelete {
+				if run == 1 {
+					sb.WriteString(" OR ")
 				} else {
-					sb.WriteString(")")
+					if run == 0 {
+						sb.WriteString("; ")
+					}
+					sb.WriteString(deleteSQL)
+					run = 1
+				}
+				sb.WriteString("(")
+				for k, idx := range pk {
+					if k > 0 {
+						sb.WriteString(" AND ")
+					}
+					sb.WriteString(pgx.Identifier{cols[idx].Name}.Sanitize())
+					sb.WriteString(" = ")
+					param(row[idx])
 				}
+				sb.WriteString(")")
+				continue
 			}
-			if j != len(item.rows)-1 || i != len(items)-1 {
+
+			if run == 0 {
 				sb.WriteString(", ")
+			} else {
+				if run == 1 {
+					sb.WriteString("; ")
+				}
+				sb.WriteString(insertSQL)
+				run = 0
+			}
+			sb.WriteString("(")
+			for k := range cols {
+				if k > 0 {
+					sb.WriteString(", ")
+				}
+				param(row[k])
 			}
+			sb.WriteString(")")
 		}
 	}
 	sb.WriteString("; FLUSH;")
 
-	var args = make([]any, 0, n)
-	for _, item := range items {
-		for _, row := range item.rows {
-			rowCopy := make([]any, len(row))
-			copy(rowCopy, row)
-			args = append(args, rowCopy...)
-		}
-	}
-
 	return sb.String(), args
 }
 
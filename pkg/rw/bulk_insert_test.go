@@ -0,0 +1,112 @@
+package rw
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type countingConn struct {
+	rows atomic.Int64
+}
+
+func (c *countingConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	time.Sleep(10 * time.Millisecond)
+	c.rows.Add(int64(len(args)))
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *countingConn) Close() {}
+
+func TestBulkInsertOperatorDrain(t *testing.T) {
+	conn := &countingConn{}
+	o := newBulkInsertOperator(context.Background(), `"t"`, []Column{{Name: "id"}}, conn, 100, zap.NewNop())
+
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int64
+	)
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := o.Insert(context.Background(), [][]any{{i}}, nil)
+			if err == nil {
+				accepted.Add(1)
+				return
+			}
+			require.ErrorIs(t, err, ErrBulkInsertClosed)
+		}()
+		if i == 25 {
+			o.Drain()
+		}
+	}
+	wg.Wait()
+
+	require.Positive(t, accepted.Load())
+	require.Equal(t, accepted.Load(), conn.rows.Load(), "every accepted insert is flushed")
+	require.ErrorIs(t, o.Insert(context.Background(), [][]any{{1}}, nil), ErrBulkInsertClosed)
+}
+
+func TestBulkInsertOperatorCloseWhileDraining(t *testing.T) {
+	o := newBulkInsertOperator(context.Background(), `"t"`, []Column{{Name: "id"}}, &countingConn{}, 100, zap.NewNop())
+
+	var wg sync.WaitGroup
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = o.Insert(context.Background(), [][]any{{i}}, nil)
+		}()
+		switch i {
+		case 50:
+			o.Drain()
+		case 60:
+			o.Close()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("inserts are still waiting after the operator was closed")
+	}
+}
+
+func TestBuildStatements(t *testing.T) {
+	cols := []Column{{Name: "id", IsPrimaryKey: true}, {Name: "name"}}
+	items := []*Item{
+		{rows: [][]any{{1, "a"}, {2, "b"}}},
+		{rows: [][]any{{1, nil}, {3, "c"}, {2, nil}}, ops: []EventOp{EventDelete, EventUpsert, EventDelete}},
+	}
+	sql, args := _buildStatements(_buildPrepareSQL(`"t"`, cols), `DELETE FROM "t" WHERE `, items, cols, []int{0})
+	require.Equal(t, `INSERT INTO "t" ("id", "name") VALUES ($1, $2), ($3, $4); `+
+		`DELETE FROM "t" WHERE ("id" = $5); `+
+		`INSERT INTO "t" ("id", "name") VALUES ($6, $7); `+
+		`DELETE FROM "t" WHERE ("id" = $8); FLUSH;`, sql)
+	require.Equal(t, []any{1, "a", 2, "b", 1, 3, "c", 2}, args)
+}
+
+func TestBuildStatementsUpsertReplacesRow(t *testing.T) {
+	cols := []Column{{Name: "id", Type: "bigint", IsPrimaryKey: true}, {Name: "name", Type: "character varying"}, {Name: "score", Type: "bigint"}}
+	p := NewEventParser(cols, nil)
+	rows, ops, err := p.Parse([][]byte{[]byte(`{"id": 1, "score": 5}`)}, EventUpsert, nil)
+	require.NoError(t, err)
+
+	// an upsert writes every column, the ones missing from the event are NULL rather than left as they were
+	sql, args := _buildStatements(_buildPrepareSQL(`"t"`, cols), `DELETE FROM "t" WHERE `, []*Item{{rows: rows, ops: ops}}, cols, []int{0})
+	require.Equal(t, `INSERT INTO "t" ("id", "name", "score") VALUES ($1, $2, $3); FLUSH;`, sql)
+	require.Len(t, args, 3)
+	require.Nil(t, args[1])
+}
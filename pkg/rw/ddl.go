@@ -0,0 +1,175 @@
+package rw
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+
+	// ErrNotBackgroundDDL is returned for statements that cannot run as background DDL.
+	ErrNotBackgroundDDL = errors.New("only a single CREATE MATERIALIZED VIEW, CREATE INDEX, CREATE SINK or CREATE TABLE statement can run in the background")
+)
+
+// backgroundKinds are the statements RisingWave can create in the background while backfilling.
+var backgroundKinds = map[string]bool{
+	"CREATE MATERIALIZED VIEW": true,
+	"CREATE INDEX":             true,
+	"CREATE SINK":              true,
+	"CREATE TABLE":             true,
+}
+
+const getRelationIDSQL = `SELECT rw_relations.id::BIGINT
+FROM rw_catalog.rw_relations
+JOIN rw_catalog.rw_schemas ON rw_schemas.id = rw_relations.schema_id
+WHERE rw_schemas.name = $1 AND rw_relations.name = $2
+`
+
+// getDDLJobIDSQL finds a job by its statement, the patterns are those of ddlJobPatterns.
+const getDDLJobIDSQL = `SELECT ddl_id::BIGINT
+FROM rw_catalog.rw_ddl_progress
+WHERE ddl_statement ILIKE $1 OR ddl_statement ILIKE $2 OR ddl_statement ILIKE $3 OR ddl_statement ILIKE $4
+ORDER BY initialized_at DESC
+LIMIT 1
+`
+
+const getDDLProgressSQL = `SELECT ddl_statement, progress, initialized_at
+FROM rw_catalog.rw_ddl_progress
+WHERE ddl_id = $1
+`
+
+// ExecuteBackgroundDDL runs a CREATE statement with background DDL enabled, so that it returns as soon as the job
+// is started instead of when the backfill is done. The id of the job is the id of the created relation.
+func (rw *RisingWave) ExecuteBackgroundDDL(ctx context.Context, sql string) (*apigen.DDLJob, error) {
+	stmts, err := statement.Split(sql)
+	if err != nil {
+		return nil, errors.Wrap(ErrNotBackgroundDDL, err.Error())
+	}
+	if len(stmts) != 1 || !backgroundKinds[stmts[0].Kind] {
+		return nil, ErrNotBackgroundDDL
+	}
+	name, ok := stmts[0].ObjectName()
+	if !ok {
+		return nil, ErrNotBackgroundDDL
+	}
+
+	// the session variable must not leak to other users of the pool, hence the dedicated connection
+	conn, err := rw.pool.Acquire(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrUnavailable, err.Error())
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SET BACKGROUND_DDL = false"); err != nil {
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}()
+
+	if _, err := query(ctx, conn, sql, true); err != nil {
+		return nil, err
+	}
+
+	id, err := backgroundJobID(ctx, conn, rw.searchPath, stmts[0].Kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := rw.DDLProgress(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Statement == nil {
+		job.Statement = &stmts[0].SQL
+	}
+	return job, nil
+}
+
+// backgroundJobID returns the id of the relation created by a background job of the given kind, e.g. CREATE
+// MATERIALIZED VIEW. Relations that are still being created may not be listed yet, their job is then looked up in the
+// progress of DDL jobs by the statement that creates the relation.
+func backgroundJobID(ctx context.Context, db DB, searchPath SearchPath, kind, name string) (int64, error) {
+	schema, relation, err := ParseName(name)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the job id of %s", name)
+	}
+	if schema == "" {
+		schema = searchPath[0]
+	}
+
+	var id int64
+	err = db.QueryRow(ctx, getRelationIDSQL, schema, relation).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		patterns := ddlJobPatterns(kind, schema, relation)
+		err = db.QueryRow(ctx, getDDLJobIDSQL, patterns[0], patterns[1], patterns[2], patterns[3]).Scan(&id)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the job id of %s", name)
+	}
+	return id, nil
+}
+
+// ddlJobPatterns returns the ILIKE patterns of the statements creating a relation: the kind, optionally IF NOT EXISTS,
+// then the name of the relation, qualified or not. Other jobs that merely refer to the relation do not match, and
+// neither do relations whose name only matches once the wildcards in it are taken as such.
+func ddlJobPatterns(kind, schema, relation string) []string {
+	var (
+		ret   = make([]string, 0, 4)
+		names = []string{
+			statement.QuoteIdent(relation),
+			statement.QuoteIdent(schema) + "." + statement.QuoteIdent(relation),
+		}
+	)
+	for _, prefix := range []string{kind + " ", kind + " IF NOT EXISTS "} {
+		for _, name := range names {
+			ret = append(ret, escapeLike(prefix+name)+" %")
+		}
+	}
+	return ret
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, with the default escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// DDLProgress reports the progress of a DDL job. A job that is not in progress anymore is done if its relation
+// exists.
+func (rw *RisingWave) DDLProgress(ctx context.Context, id int64) (*apigen.DDLJob, error) {
+	var (
+		ddl           string
+		progress      string
+		initializedAt *time.Time
+	)
+	err := rw.pool.QueryRow(ctx, getDDLProgressSQL, id).Scan(&ddl, &progress, &initializedAt)
+	if err == nil {
+		return &apigen.DDLJob{
+			Id:            id,
+			Statement:     &ddl,
+			Progress:      progress,
+			InitializedAt: initializedAt,
+		}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, queryError(err)
+	}
+
+	var exists bool
+	if err := rw.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM rw_catalog.rw_relations WHERE id = $1)", id).Scan(&exists); err != nil {
+		return nil, queryError(err)
+	}
+	if !exists {
+		return nil, errors.Wrapf(ErrNotFound, "no ddl job %d", id)
+	}
+	return &apigen.DDLJob{
+		Id:       id,
+		Progress: "100%",
+		Done:     true,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package rw
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// likeMatch matches a string against an ILIKE pattern with the default escape character.
+func likeMatch(pattern, s string) bool {
+	var re strings.Builder
+	re.WriteString("(?is)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			i++
+			re.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case '%':
+			re.WriteString(".*")
+		case '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String()).MatchString(s)
+}
+
+func TestDDLJobPatterns(t *testing.T) {
+	matches := func(patterns []string, ddl string) bool {
+		for _, p := range patterns {
+			if likeMatch(p, ddl) {
+				return true
+			}
+		}
+		return false
+	}
+
+	patterns := ddlJobPatterns("CREATE MATERIALIZED VIEW", "public", "page_views")
+	require.Len(t, patterns, 4)
+	require.Contains(t, patterns, `CREATE MATERIALIZED VIEW page\_views %`)
+	require.True(t, matches(patterns, "CREATE MATERIALIZED VIEW page_views AS SELECT * FROM clicks"))
+	require.True(t, matches(patterns, "create materialized view public.page_views as select 1"))
+	require.True(t, matches(patterns, "CREATE MATERIALIZED VIEW IF NOT EXISTS page_views AS SELECT 1"))
+
+	// the underscore is not a wildcard, and jobs that only read the relation do not match
+	require.False(t, matches(patterns, "CREATE MATERIALIZED VIEW page1views AS SELECT 1"))
+	require.False(t, matches(patterns, "CREATE MATERIALIZED VIEW top_pages AS SELECT * FROM page_views "))
+	require.False(t, matches(patterns, "CREATE SINK page_views AS SELECT 1"))
+
+	patterns = ddlJobPatterns("CREATE TABLE", "Analytics", "100%")
+	require.True(t, matches(patterns, `CREATE TABLE "Analytics"."100%" (id INT)`))
+	require.False(t, matches(patterns, `CREATE TABLE "Analytics"."1000" (id INT)`))
+}
@@ -0,0 +1,196 @@
+package rw
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/risingwavelabs/events-api/pkg/config"
+)
+
+const (
+	defaultDedupWindow  = 10 * time.Minute
+	defaultDedupMaxKeys = 1000000
+)
+
+var DedupDroppedEvents = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "events-api_rw_dedup_dropped_events",
+		Help: "The number of events dropped because their dedup column value was already ingested within the window",
+	},
+	[]string{"table"},
+)
+
+// DedupSet remembers the dedup column values of the events ingested into a table. Values are kept in two
+// generations that are rotated every window, so a value is remembered for at least the window and at most twice as
+// long. The current generation is also rotated when it holds the maximum number of values, which bounds the memory
+// at the cost of a shorter window.
+type DedupSet struct {
+	table   string
+	column  string
+	window  time.Duration
+	maxKeys int
+	clock   func() time.Time
+
+	mu      sync.Mutex
+	rotated time.Time
+	cur     map[string]struct{}
+	prev    map[string]struct{}
+
+	// pending are the values of the ingests in progress, the channel is closed once the ingest is done
+	pending map[string]chan struct{}
+}
+
+func newDedupSet(rule config.DedupRule, searchPath SearchPath) (*DedupSet, error) {
+	if rule.Table == "" || rule.Column == "" {
+		return nil, errors.New("dedup rules need a table and a column")
+	}
+	s := &DedupSet{
+		table:   searchPath.QualifiedName(rule.Table),
+		column:  rule.Column,
+		window:  defaultDedupWindow,
+		maxKeys: defaultDedupMaxKeys,
+		clock:   time.Now,
+		cur:     make(map[string]struct{}),
+		prev:    make(map[string]struct{}),
+		pending: make(map[string]chan struct{}),
+	}
+	if rule.Window != "" {
+		d, err := time.ParseDuration(rule.Window)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid dedup window of %s", rule.Table)
+		}
+		if d <= 0 {
+			return nil, errors.Errorf("invalid dedup window %s of %s", rule.Window, rule.Table)
+		}
+		s.window = d
+	}
+	if rule.MaxKeys > 0 {
+		s.maxKeys = rule.MaxKeys
+	}
+	s.rotated = s.clock()
+	return s, nil
+}
+
+// dedupKey returns the key of a column value, events without a value have no key and are never dropped.
+func dedupKey(v any) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%T:%v", v, v), true
+}
+
+// Claim returns which events to keep out of the ones with the given keys, ok is false for events without a key.
+// Events whose key was ingested within the window are dropped, and so are the repetitions of a key. If another
+// ingest with one of the keys is in progress, Claim waits for it to be done. The returned function must be called
+// once the kept events are ingested, or failed to be, in which case their keys are forgotten.
+func (s *DedupSet) Claim(ctx context.Context, keys []string, ok []bool) ([]bool, func(ingested bool), error) {
+	for {
+		s.mu.Lock()
+		s.rotate(s.clock())
+
+		var wait chan struct{}
+		for i, key := range keys {
+			if ch, pending := s.pending[key]; ok[i] && pending {
+				wait = ch
+				break
+			}
+		}
+		if wait == nil {
+			keep, done := s.claim(keys, ok)
+			s.mu.Unlock()
+			return keep, done, nil
+		}
+		s.mu.Unlock()
+
+		// the keys are claimed all at once or not at all, so ingests never wait on each other in a cycle
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// claim must be called with the lock held and none of the keys pending.
+func (s *DedupSet) claim(keys []string, ok []bool) ([]bool, func(bool)) {
+	var (
+		keep    = make([]bool, len(keys))
+		claimed []string
+		ch      = make(chan struct{})
+	)
+	for i, key := range keys {
+		if !ok[i] {
+			keep[i] = true
+			continue
+		}
+		if _, pending := s.pending[key]; pending || s.contains(key) {
+			continue
+		}
+		s.pending[key] = ch
+		claimed = append(claimed, key)
+		keep[i] = true
+	}
+
+	return keep, func(ingested bool) {
+		s.mu.Lock()
+		for _, key := range claimed {
+			delete(s.pending, key)
+			if ingested {
+				s.add(key)
+			}
+		}
+		s.mu.Unlock()
+		close(ch)
+	}
+}
+
+func (s *DedupSet) contains(key string) bool {
+	if _, ok := s.cur[key]; ok {
+		return true
+	}
+	_, ok := s.prev[key]
+	return ok
+}
+
+func (s *DedupSet) add(key string) {
+	if len(s.cur) >= s.maxKeys {
+		s.prev, s.cur = s.cur, make(map[string]struct{})
+		s.rotated = s.clock()
+	}
+	s.cur[key] = struct{}{}
+}
+
+func (s *DedupSet) rotate(now time.Time) {
+	elapsed := now.Sub(s.rotated)
+	if elapsed < s.window {
+		return
+	}
+	if elapsed >= 2*s.window {
+		s.prev = make(map[string]struct{})
+	} else {
+		s.prev = s.cur
+	}
+	s.cur = make(map[string]struct{})
+	s.rotated = now
+}
+
+// newDedupSets returns the dedup sets of the configuration keyed by the canonical table name.
+func newDedupSets(cfg *config.Config, searchPath SearchPath) (map[string]*DedupSet, error) {
+	ret := make(map[string]*DedupSet)
+	for _, rule := range cfg.Ingest.Dedup {
+		s, err := newDedupSet(rule, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ret[s.table]; ok {
+			return nil, errors.Errorf("duplicate dedup rule of %s", s.table)
+		}
+		ret[s.table] = s
+	}
+	return ret, nil
+}
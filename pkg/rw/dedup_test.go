@@ -0,0 +1,70 @@
+package rw
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupSet(t *testing.T) {
+	s, err := newDedupSet(config.DedupRule{Table: "events", Column: "id", Window: "1m", MaxKeys: 3}, SearchPath{"public"})
+	require.NoError(t, err)
+	now := time.Now()
+	s.clock = func() time.Time { return now }
+	s.rotated = now
+	ctx := context.Background()
+
+	keep, done, err := s.Claim(ctx, []string{"a", "b", "a", ""}, []bool{true, true, true, false})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true, false, true}, keep)
+	done(true)
+
+	keep, done, err = s.Claim(ctx, []string{"a", "c"}, []bool{true, true})
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true}, keep)
+	done(false)
+
+	// c failed to be ingested, so it is not a duplicate
+	keep, done, err = s.Claim(ctx, []string{"c"}, []bool{true})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true}, keep)
+	done(true)
+
+	// a is remembered for at least the window and at most twice as long
+	now = now.Add(90 * time.Second)
+	keep, done, err = s.Claim(ctx, []string{"a"}, []bool{true})
+	require.NoError(t, err)
+	require.Equal(t, []bool{false}, keep)
+	done(true)
+
+	now = now.Add(2 * time.Minute)
+	keep, done, err = s.Claim(ctx, []string{"a"}, []bool{true})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true}, keep)
+	done(true)
+}
+
+func TestDedupSetPending(t *testing.T) {
+	s, err := newDedupSet(config.DedupRule{Table: "events", Column: "id"}, SearchPath{"public"})
+	require.NoError(t, err)
+
+	_, done, err := s.Claim(context.Background(), []string{"a"}, []bool{true})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = s.Claim(ctx, []string{"b", "a"}, []bool{true, true})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	result := make(chan []bool)
+	go func() {
+		keep, done, _ := s.Claim(context.Background(), []string{"b", "a"}, []bool{true, true})
+		done(true)
+		result <- keep
+	}()
+	done(true)
+	require.Equal(t, []bool{true, false}, <-result)
+}
@@ -0,0 +1,303 @@
+package rw
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	MIMENDJSON      = "application/x-ndjson"
+	MIMECSV         = "text/csv"
+	MIMEArrowStream = "application/vnd.apache.arrow.stream"
+
+	arrowBatchSize = 4096
+)
+
+// RowEncoder writes query results in a streaming output format.
+type RowEncoder interface {
+	Begin(cols []Column) error
+	Encode(values []any) error
+	// End finishes the output. err is the error that interrupted the stream, if any, and is reported in-band
+	// when the format allows it.
+	End(err error) error
+}
+
+// NewRowEncoder returns the encoder of the given MIME type.
+func NewRowEncoder(mime string, w io.Writer) (RowEncoder, error) {
+	switch mime {
+	case MIMENDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	case MIMECSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case MIMEArrowStream:
+		return &arrowEncoder{w: w}, nil
+	}
+	return nil, errors.Errorf("unsupported output format %s", mime)
+}
+
+type ndjsonEncoder struct {
+	enc  *json.Encoder
+	cols []Column
+}
+
+func (e *ndjsonEncoder) Begin(cols []Column) error {
+	e.cols = cols
+	return nil
+}
+
+func (e *ndjsonEncoder) Encode(values []any) error {
+	return e.enc.Encode(JSONRow(e.cols, values))
+}
+
+// End writes a trailing {"error": ...} line if the stream was interrupted.
+func (e *ndjsonEncoder) End(err error) error {
+	if err == nil {
+		return nil
+	}
+	return e.enc.Encode(map[string]string{"error": err.Error()})
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	cols   []Column
+	record []string
+}
+
+func (e *csvEncoder) Begin(cols []Column) error {
+	e.cols = cols
+	e.record = make([]string, len(cols))
+	for i, c := range cols {
+		e.record[i] = c.Name
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Encode(values []any) error {
+	for i, v := range values {
+		s, err := textValue(JSONValue(e.cols[i].Type, v))
+		if err != nil {
+			return err
+		}
+		e.record[i] = s
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) End(err error) error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// textValue formats a value converted by JSONValue as text. NULL is an empty string, arrays and JSON objects are
+// written as JSON.
+func textValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case map[string]any, []any:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+type arrowEncoder struct {
+	w    io.Writer
+	cols []Column
+	ipc  *ipc.Writer
+	rb   *array.RecordBuilder
+	n    int
+}
+
+func arrowType(col Column) arrow.DataType {
+	switch col.Type {
+	case "boolean":
+		return arrow.FixedWidthTypes.Boolean
+	case "smallint":
+		return arrow.PrimitiveTypes.Int16
+	case "integer":
+		return arrow.PrimitiveTypes.Int32
+	case "bigint":
+		return arrow.PrimitiveTypes.Int64
+	case "real":
+		return arrow.PrimitiveTypes.Float32
+	case "double precision":
+		return arrow.PrimitiveTypes.Float64
+	case "timestamp":
+		return &arrow.TimestampType{Unit: arrow.Microsecond}
+	case "timestamptz":
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	case "date":
+		return arrow.FixedWidthTypes.Date32
+	case "time":
+		return arrow.FixedWidthTypes.Time64us
+	case "interval":
+		return arrow.FixedWidthTypes.MonthDayNanoInterval
+	case "bytea":
+		return arrow.BinaryTypes.Binary
+	}
+	if elem, ok := strings.CutSuffix(col.Type, "[]"); ok {
+		return arrow.ListOf(arrowType(Column{Type: elem}))
+	}
+	// numeric is a string as its precision and scale are not fixed, so are jsonb, uuid, structs and rw_int256
+	return arrow.BinaryTypes.String
+}
+
+func (e *arrowEncoder) Begin(cols []Column) error {
+	e.cols = cols
+	fields := make([]arrow.Field, len(cols))
+	for i, c := range cols {
+		fields[i] = arrow.Field{Name: c.Name, Type: arrowType(c), Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	e.rb = array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	e.ipc = ipc.NewWriter(e.w, ipc.WithSchema(schema))
+	return nil
+}
+
+func (e *arrowEncoder) Encode(values []any) error {
+	for i, v := range values {
+		if err := appendArrow(e.rb.Field(i), e.cols[i].Type, v); err != nil {
+			return errors.Wrapf(err, "column %s", e.cols[i].Name)
+		}
+	}
+	e.n++
+	if e.n >= arrowBatchSize {
+		return e.writeBatch()
+	}
+	return nil
+}
+
+func (e *arrowEncoder) writeBatch() error {
+	rec := e.rb.NewRecordBatch()
+	defer rec.Release()
+	e.n = 0
+	return e.ipc.Write(rec)
+}
+
+// End writes the last batch and the end-of-stream marker. The marker is left out if the stream was interrupted
+// so that readers see a truncated stream instead of a complete one.
+func (e *arrowEncoder) End(err error) error {
+	defer e.rb.Release()
+	if err != nil {
+		return nil
+	}
+	if e.n > 0 {
+		if err := e.writeBatch(); err != nil {
+			return err
+		}
+	}
+	return e.ipc.Close()
+}
+
+func appendArrow(b array.Builder, typ string, v any) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		if v, ok := v.(bool); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int16Builder:
+		if v, ok := v.(int16); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int32Builder:
+		if v, ok := v.(int32); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int64Builder:
+		if v, ok := v.(int64); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Float32Builder:
+		if v, ok := v.(float32); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Float64Builder:
+		if v, ok := v.(float64); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.TimestampBuilder:
+		if v, ok := v.(time.Time); ok {
+			ts, err := arrow.TimestampFromTime(v, arrow.Microsecond)
+			if err != nil {
+				return err
+			}
+			b.Append(ts)
+			return nil
+		}
+	case *array.Date32Builder:
+		if v, ok := v.(time.Time); ok {
+			b.Append(arrow.Date32FromTime(v))
+			return nil
+		}
+	case *array.Time64Builder:
+		if v, ok := v.(pgtype.Time); ok {
+			b.Append(arrow.Time64(v.Microseconds))
+			return nil
+		}
+	case *array.MonthDayNanoIntervalBuilder:
+		if v, ok := v.(pgtype.Interval); ok {
+			b.Append(arrow.MonthDayNanoInterval{Months: v.Months, Days: v.Days, Nanoseconds: v.Microseconds * 1000})
+			return nil
+		}
+	case *array.BinaryBuilder:
+		if v, ok := v.([]byte); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.ListBuilder:
+		if v, ok := v.([]any); ok {
+			b.Append(true)
+			elemTyp := strings.TrimSuffix(typ, "[]")
+			for _, e := range v {
+				if err := appendArrow(b.ValueBuilder(), elemTyp, e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *array.StringBuilder:
+		s, err := textValue(JSONValue(typ, v))
+		if err != nil {
+			return err
+		}
+		b.Append(s)
+		return nil
+	}
+	return errors.Errorf("unexpected value %T for arrow type %s", v, b.Type())
+}
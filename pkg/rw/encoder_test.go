@@ -0,0 +1,64 @@
+package rw
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{
+	{Name: "id", Type: "bigint"},
+	{Name: "name", Type: "varchar"},
+	{Name: "ts", Type: "timestamptz"},
+}
+
+var testRows = [][]any{
+	{int64(1), "a,b", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+	{int64(2), nil, nil},
+}
+
+func encodeTestRows(t *testing.T, mime string, err error) []byte {
+	var buf bytes.Buffer
+	enc, e := NewRowEncoder(mime, &buf)
+	require.NoError(t, e)
+	require.NoError(t, enc.Begin(testColumns))
+	for _, row := range testRows {
+		require.NoError(t, enc.Encode(row))
+	}
+	require.NoError(t, enc.End(err))
+	return buf.Bytes()
+}
+
+func TestCSVEncoder(t *testing.T) {
+	require.Equal(t, "id,name,ts\n1,\"a,b\",2024-01-15T10:30:00Z\n2,,\n", string(encodeTestRows(t, MIMECSV, nil)))
+}
+
+func TestNDJSONEncoder(t *testing.T) {
+	out := encodeTestRows(t, MIMENDJSON, errors.New("boom"))
+	require.Equal(t, `{"id":1,"name":"a,b","ts":"2024-01-15T10:30:00Z"}
+{"id":2,"name":null,"ts":null}
+{"error":"boom"}
+`, string(out))
+}
+
+func TestArrowEncoder(t *testing.T) {
+	r, err := ipc.NewReader(bytes.NewReader(encodeTestRows(t, MIMEArrowStream, nil)))
+	require.NoError(t, err)
+	defer r.Release()
+
+	require.True(t, r.Next())
+	rec := r.RecordBatch()
+	require.EqualValues(t, 2, rec.NumRows())
+	require.Equal(t, int64(1), rec.Column(0).(*array.Int64).Value(0))
+	require.Equal(t, "a,b", rec.Column(1).(*array.String).Value(0))
+	require.True(t, rec.Column(1).IsNull(1))
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), rec.Column(2).(*array.Timestamp).Value(0).ToTime(arrow.Microsecond))
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
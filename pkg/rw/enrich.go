@@ -0,0 +1,317 @@
+package rw
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/enrich"
+)
+
+// EnrichSource is a value of the request events are ingested by, or derived from their User-Agent or IP, which fills
+// a column instead of the events.
+type EnrichSource string
+
+const (
+	// EnrichReceivedAt is when the server received the request.
+	EnrichReceivedAt EnrichSource = "received_at"
+	EnrichRequestID  EnrichSource = "request_id"
+	// EnrichClientIP is the IP of the client, behind trusted proxies the one they forwarded.
+	EnrichClientIP  EnrichSource = "client_ip"
+	EnrichUserAgent EnrichSource = "user_agent"
+	// EnrichAPIKey is the id of the API key of the request.
+	EnrichAPIKey EnrichSource = "api_key"
+
+	// The sources parsed from the User-Agent, see enrich.ParseUserAgent.
+	EnrichBrowser        EnrichSource = "browser"
+	EnrichBrowserVersion EnrichSource = "browser_version"
+	EnrichOS             EnrichSource = "os"
+	EnrichOSVersion      EnrichSource = "os_version"
+	EnrichDevice         EnrichSource = "device"
+
+	// The sources looked up from the IP in the GeoIP databases, see enrich.GeoIP.
+	EnrichCountry EnrichSource = "country"
+	EnrichRegion  EnrichSource = "region"
+	EnrichCity    EnrichSource = "city"
+	EnrichASN     EnrichSource = "asn"
+	EnrichASOrg   EnrichSource = "as_org"
+)
+
+// enrichKind tells what a source is taken from.
+type enrichKind int
+
+const (
+	enrichFromRequest enrichKind = iota + 1
+	enrichFromUserAgent
+	enrichFromCityDB
+	enrichFromASNDB
+)
+
+// EnrichColumnPrefix names the columns filled without configuration, e.g. _ingest_received_at.
+const EnrichColumnPrefix = "_ingest_"
+
+var enrichSources = map[EnrichSource]enrichKind{
+	EnrichReceivedAt:     enrichFromRequest,
+	EnrichRequestID:      enrichFromRequest,
+	EnrichClientIP:       enrichFromRequest,
+	EnrichUserAgent:      enrichFromRequest,
+	EnrichAPIKey:         enrichFromRequest,
+	EnrichBrowser:        enrichFromUserAgent,
+	EnrichBrowserVersion: enrichFromUserAgent,
+	EnrichOS:             enrichFromUserAgent,
+	EnrichOSVersion:      enrichFromUserAgent,
+	EnrichDevice:         enrichFromUserAgent,
+	EnrichCountry:        enrichFromCityDB,
+	EnrichRegion:         enrichFromCityDB,
+	EnrichCity:           enrichFromCityDB,
+	EnrichASN:            enrichFromASNDB,
+	EnrichASOrg:          enrichFromASNDB,
+}
+
+func ParseEnrichSource(s string) (EnrichSource, error) {
+	if src := EnrichSource(s); enrichSources[src] != 0 {
+		return src, nil
+	}
+	return "", errors.Errorf("unknown enrichment source %q", s)
+}
+
+// RequestMeta is what the server knows about the request events are ingested by. Empty values are NULL.
+type RequestMeta struct {
+	ReceivedAt time.Time
+	RequestID  string
+	ClientIP   string
+	UserAgent  string
+	APIKeyID   string
+}
+
+// value returns the value of a source for a column of the given type. Timestamps are given as a time for
+// timestamptz, in UTC for timestamp, in unix milliseconds for bigint and in RFC 3339 otherwise.
+func (m *RequestMeta) value(src EnrichSource, typ string) any {
+	if m == nil {
+		return nil
+	}
+	var s string
+	switch src {
+	case EnrichReceivedAt:
+		if m.ReceivedAt.IsZero() {
+			return nil
+		}
+		switch typ {
+		case "timestamptz", "timestamp with time zone":
+			return m.ReceivedAt
+		case "timestamp", "timestamp without time zone":
+			return m.ReceivedAt.UTC().Format("2006-01-02 15:04:05.999999")
+		case "bigint":
+			return m.ReceivedAt.UnixMilli()
+		}
+		return m.ReceivedAt.UTC().Format(time.RFC3339Nano)
+	case EnrichRequestID:
+		s = m.RequestID
+	case EnrichClientIP:
+		s = m.ClientIP
+	case EnrichUserAgent:
+		s = m.UserAgent
+	case EnrichAPIKey:
+		s = m.APIKeyID
+	}
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// enrichColumn is a column of the parsed rows filled from the request.
+type enrichColumn struct {
+	idx    int
+	typ    string
+	source EnrichSource
+}
+
+// Enrichment are the columns of a table filled by the server instead of the events.
+type Enrichment struct {
+	Columns map[string]EnrichSource
+
+	// UserAgentColumn and IPColumn hold what the derived sources are taken from, if empty the User-Agent header and
+	// the client IP of the request
+	UserAgentColumn string
+	IPColumn        string
+}
+
+// enrichRules returns the enrichments of the configuration keyed by the canonical table name. The GeoIP sources need
+// the database they are looked up in.
+func enrichRules(cfg *config.Config, searchPath SearchPath, geo *enrich.GeoIP) (map[string]Enrichment, error) {
+	ret := make(map[string]Enrichment)
+	for _, rule := range cfg.Ingest.Enrich {
+		if rule.Table == "" {
+			return nil, errors.New("enrich rules need a table")
+		}
+		key := searchPath.QualifiedName(rule.Table)
+		if _, ok := ret[key]; ok {
+			return nil, errors.Errorf("duplicate enrich rule of %s", key)
+		}
+		cols := make(map[string]EnrichSource, len(rule.Columns))
+		for col, s := range rule.Columns {
+			src, err := ParseEnrichSource(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid enrich rule of %s.%s", key, col)
+			}
+			if !enrichAvailable(src, geo) {
+				return nil, errors.Errorf("invalid enrich rule of %s.%s: %s needs a geoip database", key, col, src)
+			}
+			cols[col] = src
+		}
+		ret[key] = Enrichment{
+			Columns:         cols,
+			UserAgentColumn: rule.UserAgentColumn,
+			IPColumn:        rule.IPColumn,
+		}
+	}
+	return ret, nil
+}
+
+// enrichAvailable reports whether the values of a source can be known, the GeoIP sources need their database.
+func enrichAvailable(src EnrichSource, geo *enrich.GeoIP) bool {
+	switch enrichSources[src] {
+	case enrichFromCityDB:
+		return geo.HasCity()
+	case enrichFromASNDB:
+		return geo.HasASN()
+	}
+	return true
+}
+
+// enrichedColumns returns the columns filled by the server, the configured ones and those named after a source with
+// EnrichColumnPrefix. configured may be nil.
+func enrichedColumns(cols []Column, configured map[string]EnrichSource) map[string]EnrichSource {
+	ret := make(map[string]EnrichSource)
+	for _, c := range cols {
+		if !Ingestible(c) {
+			continue
+		}
+		if src, ok := configured[c.Name]; ok {
+			ret[c.Name] = src
+			continue
+		}
+		if name, ok := strings.CutPrefix(c.Name, EnrichColumnPrefix); ok && enrichSources[EnrichSource(name)] != 0 {
+			ret[c.Name] = EnrichSource(name)
+		}
+	}
+	return ret
+}
+
+// deriver fills the columns derived from the User-Agent and the IP of the events, between parsing and inserting them.
+type deriver struct {
+	cols []enrichColumn
+	geo  *enrich.GeoIP
+
+	// uaIdx and ipIdx are the columns the inputs are taken from, -1 for the request metadata. -2 if the configured
+	// column does not exist, the derived columns are then NULL.
+	uaIdx int
+	ipIdx int
+}
+
+// newDeriver returns the deriver of the columns of the parser, nil if no column is derived.
+func newDeriver(p *EventParser, e Enrichment, geo *enrich.GeoIP) *deriver {
+	d := &deriver{geo: geo, uaIdx: -1, ipIdx: -1}
+	for i, c := range p.cols {
+		if src, ok := e.Columns[c.Name]; ok {
+			if kind := enrichSources[src]; kind != enrichFromRequest {
+				d.cols = append(d.cols, enrichColumn{idx: i, typ: c.Type, source: src})
+			}
+		}
+	}
+	if len(d.cols) == 0 {
+		return nil
+	}
+	column := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		if idx, ok := p.cidx[name]; ok {
+			return idx
+		}
+		return -2
+	}
+	d.uaIdx = column(e.UserAgentColumn)
+	d.ipIdx = column(e.IPColumn)
+	return d
+}
+
+// input returns the User-Agent or IP of a row.
+func input(row []any, idx int, fallback string) string {
+	switch idx {
+	case -1:
+		return fallback
+	case -2:
+		return ""
+	}
+	s, _ := row[idx].(string)
+	return s
+}
+
+// derive fills the derived columns of the rows. Rows usually share their inputs, so the last results are reused.
+func (d *deriver) derive(rows [][]any, meta *RequestMeta) {
+	var (
+		fallbackUA, fallbackIP string
+
+		lastUA, lastIP  string
+		ua              enrich.UserAgent
+		loc             enrich.Location
+		parsed, located bool
+	)
+	if meta != nil {
+		fallbackUA, fallbackIP = meta.UserAgent, meta.ClientIP
+	}
+	for _, row := range rows {
+		if s := input(row, d.uaIdx, fallbackUA); s != lastUA || !parsed {
+			ua, lastUA, parsed = enrich.ParseUserAgent(s), s, true
+		}
+		if s := input(row, d.ipIdx, fallbackIP); s != lastIP || !located {
+			loc, _ = d.geo.Lookup(s)
+			lastIP, located = s, true
+		}
+		for _, c := range d.cols {
+			row[c.idx] = derivedValue(c, &ua, &loc)
+		}
+	}
+}
+
+// derivedValue returns the value of a derived column, unknown values are NULL. The ASN is a number in integer columns.
+func derivedValue(c enrichColumn, ua *enrich.UserAgent, loc *enrich.Location) any {
+	var s string
+	switch c.source {
+	case EnrichBrowser:
+		s = ua.Browser
+	case EnrichBrowserVersion:
+		s = ua.BrowserVersion
+	case EnrichOS:
+		s = ua.OS
+	case EnrichOSVersion:
+		s = ua.OSVersion
+	case EnrichDevice:
+		s = ua.Device
+	case EnrichCountry:
+		s = loc.Country
+	case EnrichRegion:
+		s = loc.Region
+	case EnrichCity:
+		s = loc.City
+	case EnrichASN:
+		if loc.ASN == 0 {
+			return nil
+		}
+		switch c.typ {
+		case "integer", "bigint":
+			return int64(loc.ASN)
+		}
+		s = strconv.FormatUint(uint64(loc.ASN), 10)
+	case EnrichASOrg:
+		s = loc.ASOrg
+	}
+	if s == "" {
+		return nil
+	}
+	return s
+}
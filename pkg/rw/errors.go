@@ -0,0 +1,67 @@
+package rw
+
+import (
+	"context"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrQueryFailed      = errors.New("query failed")
+	ErrRelationNotFound = errors.New("relation not found")
+	ErrInvalidEvent     = errors.New("invalid event")
+
+	// ErrIngestionDisabled is returned when events are sent to a table that is excluded by the configuration or
+	// disabled at runtime.
+	ErrIngestionDisabled = errors.New("ingestion disabled")
+	ErrUnavailable       = errors.New("risingwave is unavailable")
+
+	// ErrReadOnlyTransaction is returned for a batch run in a transaction with a statement that is not read-only,
+	// RisingWave only supports read-only transactions.
+	ErrReadOnlyTransaction = errors.New("only read-only statements can run in a transaction")
+
+	// ErrQueryTimeout and ErrQueryCanceled are the causes of the cancellation of query contexts.
+	ErrQueryTimeout  = errors.New("statement timeout")
+	ErrQueryCanceled = errors.New("query canceled")
+)
+
+// QueryError is an error of a statement, it matches ErrQueryFailed.
+type QueryError struct {
+	err error
+}
+
+func (e *QueryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *QueryError) Unwrap() []error {
+	return []error{ErrQueryFailed, e.err}
+}
+
+// queryError classifies an error of running a statement. Errors of the connection to RisingWave make it unavailable,
+// cancellations are returned as is and anything else is an error of the statement.
+func queryError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var (
+		pgErr   *pgconn.PgError
+		connErr *pgconn.ConnectError
+		netErr  net.Error
+	)
+	if !errors.As(err, &pgErr) && (errors.As(err, &connErr) || errors.As(err, &netErr)) {
+		return errors.Wrap(ErrUnavailable, err.Error())
+	}
+	return &QueryError{err: err}
+}
+
+// SQLState returns the SQLSTATE code RisingWave reported for the error, or an empty string.
+func SQLState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
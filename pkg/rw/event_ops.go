@@ -0,0 +1,54 @@
+package rw
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EventOp is the operation an event applies to its table.
+type EventOp uint8
+
+const (
+	// EventInsert appends the event as a row. On tables with a primary key RisingWave overwrites the row with the same
+	// key, unless the table is created with a different ON CONFLICT behavior.
+	EventInsert EventOp = iota
+
+	// EventUpsert inserts or overwrites the row with the primary key of the event, it is only allowed on tables with a
+	// primary key.
+	EventUpsert
+
+	// EventDelete deletes the row with the primary key of the event, other fields are ignored.
+	EventDelete
+)
+
+// EventOpField is the event field selecting the operation of the event, unless the table has a column of that name.
+const EventOpField = "_op"
+
+var eventOpNames = map[string]EventOp{
+	"insert": EventInsert,
+	"upsert": EventUpsert,
+	"delete": EventDelete,
+}
+
+// ParseEventOp parses the name of an operation, an empty name is an insert.
+func ParseEventOp(s string) (EventOp, error) {
+	if s == "" {
+		return EventInsert, nil
+	}
+	op, ok := eventOpNames[strings.ToLower(s)]
+	if !ok {
+		return EventInsert, errors.Wrapf(ErrInvalidEvent, "unknown operation %q, expected insert, upsert or delete", s)
+	}
+	return op, nil
+}
+
+func (op EventOp) String() string {
+	switch op {
+	case EventUpsert:
+		return "upsert"
+	case EventDelete:
+		return "delete"
+	}
+	return "insert"
+}
@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/risingwavelabs/events-api/pkg/closer"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/enrich"
 	"github.com/risingwavelabs/events-api/pkg/gctx"
 	"go.uber.org/zap"
 )
@@ -16,66 +24,149 @@ import (
 type EventParser struct {
 	cidx  map[string]int
 	cType map[string]string
+	cols  []Column
+	pk    []int
+
+	// opField is false if the table has a column named like EventOpField, the operation then comes from the request
+	opField bool
+
+	// enrich are the columns filled from the request metadata, whatever the events set
+	enrich []enrichColumn
 }
 
-func NewEventParser(cols []Column) *EventParser {
+// NewEventParser creates the parser of the events of the columns, enriched are the columns filled by the server. The
+// parser fills those taken from the request metadata, the derived ones are left to the handler.
+func NewEventParser(cols []Column, enriched map[string]EnrichSource) *EventParser {
 	cidx := make(map[string]int)
 	cType := make(map[string]string)
+	var (
+		pk   []int
+		fill []enrichColumn
+	)
 	for i, col := range cols {
 		cidx[col.Name] = i
 		cType[col.Name] = col.Type
+		if col.IsPrimaryKey {
+			pk = append(pk, i)
+		}
+		if src, ok := enriched[col.Name]; ok && enrichSources[src] == enrichFromRequest {
+			fill = append(fill, enrichColumn{idx: i, typ: col.Type, source: src})
+		}
 	}
+	_, hasOpColumn := cidx[EventOpField]
 
 	return &EventParser{
-		cidx:  cidx,
-		cType: cType,
+		cidx:    cidx,
+		cType:   cType,
+		cols:    cols,
+		pk:      pk,
+		opField: !hasOpColumn,
+		enrich:  fill,
 	}
 }
 
-func (p *EventParser) Parse(lines [][]byte) ([][]any, error) {
-	result := make([][]any, 0, len(lines))
+// Parse returns the rows of the events and their operations, which default to op. The operations are nil if all
+// events are inserts. The columns taken from the request metadata are filled from meta.
+func (p *EventParser) Parse(lines [][]byte, op EventOp, meta *RequestMeta) ([][]any, []EventOp, error) {
+	var (
+		result = make([][]any, 0, len(lines))
+		ops    []EventOp
+		values = make([]any, len(p.enrich))
+	)
+	for i, c := range p.enrich {
+		values[i] = meta.value(c.source, c.typ)
+	}
 	for _, line := range lines {
 		if len(bytes.Trim(line, " \n\r\t\r")) == 0 {
 			continue
 		}
-		v, err := p.extractValues(line)
+		v, lineOp, err := p.extractValues(line, op)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to extract values from line")
+			return nil, nil, errors.Wrap(err, "failed to extract values from line")
+		}
+		for i, c := range p.enrich {
+			v[c.idx] = values[i]
+		}
+		if lineOp != EventInsert && ops == nil {
+			ops = make([]EventOp, len(result), len(lines))
+		}
+		if ops != nil {
+			ops = append(ops, lineOp)
 		}
 		result = append(result, v)
 	}
-	return result, nil
+	return result, ops, nil
 }
 
-func (p *EventParser) extractValues(line []byte) ([]any, error) {
+func (p *EventParser) extractValues(line []byte, op EventOp) ([]any, EventOp, error) {
 	ret := make([]any, len(p.cidx))
 	m := NewLiteMap(p.cType)
 	if err := m.UnmarshalJSON(line); err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal json: %s", string(line))
+		return nil, op, errors.Wrapf(err, "failed to unmarshal json: %s", string(line))
 	}
 	for key, value := range m.data {
 		if idx, ok := p.cidx[key]; ok {
 			ret[idx] = value
 		}
 	}
-	return ret, nil
+
+	if v, ok := m.data[EventOpField]; ok && p.opField && v != nil {
+		name, ok := v.(string)
+		if !ok {
+			return nil, op, errors.Errorf("%s must be a string", EventOpField)
+		}
+		var err error
+		if op, err = ParseEventOp(name); err != nil {
+			return nil, op, err
+		}
+	}
+	if op == EventInsert {
+		return ret, op, nil
+	}
+	if len(p.pk) == 0 {
+		return nil, op, errors.Errorf("%s needs a primary key, the table has none", op)
+	}
+	if op == EventDelete {
+		for _, idx := range p.pk {
+			if ret[idx] == nil {
+				return nil, op, errors.Errorf("delete needs the primary key column %s", p.cols[idx].Name)
+			}
+		}
+	}
+	return ret, op, nil
 }
 
 type EventHandler struct {
 	bio    *BulkInsertOperator
 	parser *EventParser
+
+	// deriver fills the columns derived from the User-Agent and IP of the events, if any
+	deriver *deriver
+
+	// dedup drops the inserted events whose value of the column at dedupIdx was ingested within its window, if set
+	dedup    *DedupSet
+	dedupIdx int
+
+	// inUse counts the ingests holding the handler, lastUsed is when one last acquired or released it in unix nanos
+	inUse    atomic.Int32
+	lastUsed atomic.Int64
+}
+
+// Ingestible reports whether events set the column, system columns such as _row_id and _rw_timestamp are filled in
+// by RisingWave.
+func Ingestible(c Column) bool {
+	return c.Name != "_row_id" && !strings.HasPrefix(c.Name, "_rw")
 }
 
-func NewEventHandler(table string, cols []Column, bim *BulkInsertManager) (*EventHandler, error) {
+// NewEventHandler creates the handler of a table, table is its quoted SQL identifier. Events are deduplicated by the
+// column of dedup if it is not nil and the table has the column, the columns of the enrichment are filled by the
+// server with geo for the GeoIP sources.
+func NewEventHandler(table string, cols []Column, bim *BulkInsertManager, dedup *DedupSet, enrichment Enrichment, geo *enrich.GeoIP) (*EventHandler, error) {
 	filteredCols := []Column{}
 	for _, c := range cols {
-		if c.Name == "_row_id" {
-			continue
+		if Ingestible(c) {
+			filteredCols = append(filteredCols, c)
 		}
-		if strings.HasPrefix(c.Name, "_rw") {
-			continue
-		}
-		filteredCols = append(filteredCols, c)
 	}
 
 	bio, err := bim.NewBulkInsertOperator(table, filteredCols)
@@ -83,115 +174,474 @@ func NewEventHandler(table string, cols []Column, bim *BulkInsertManager) (*Even
 		return nil, errors.Wrap(err, "failed to create bulk insert operator")
 	}
 
-	return &EventHandler{
-		bio:    bio,
-		parser: NewEventParser(filteredCols),
-	}, nil
+	parser := NewEventParser(filteredCols, enrichment.Columns)
+	handler := &EventHandler{
+		bio:     bio,
+		parser:  parser,
+		deriver: newDeriver(parser, enrichment, geo),
+	}
+	if dedup != nil {
+		if idx, ok := handler.parser.cidx[dedup.column]; ok {
+			handler.dedup, handler.dedupIdx = dedup, idx
+		}
+	}
+	return handler, nil
 }
 
-func (i *EventHandler) Ingest(ctx context.Context, lines [][]byte) error {
-	rows, err := i.parser.Parse(lines)
+// Ingest writes the events, op is the operation of the events that do not set one.
+func (i *EventHandler) Ingest(ctx context.Context, lines [][]byte, op EventOp, meta *RequestMeta) error {
+	rows, ops, err := i.parser.Parse(lines, op, meta)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse lines")
+		return errors.Wrap(ErrInvalidEvent, err.Error())
+	}
+	if i.deriver != nil {
+		i.deriver.derive(rows, meta)
+	}
+	if i.dedup == nil {
+		return i.insert(ctx, rows, ops)
 	}
 
-	if err := i.bio.Insert(ctx, rows); err != nil {
-		return errors.Wrap(err, "failed to insert event")
+	rows, ops, done, err := i.deduplicate(ctx, rows, ops)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		done(true)
+		return nil
 	}
+	err = i.insert(ctx, rows, ops)
+	done(err == nil)
+	return err
+}
 
+func (i *EventHandler) insert(ctx context.Context, rows [][]any, ops []EventOp) error {
+	if err := i.bio.Insert(ctx, rows, ops); err != nil {
+		return errors.Wrap(err, "failed to insert event")
+	}
 	return nil
 }
 
+// deduplicate drops the inserted rows whose dedup value was already ingested, upserts and deletes are kept as they
+// are idempotent by the primary key. done must be called once the kept rows are ingested, see DedupSet.Claim.
+func (i *EventHandler) deduplicate(ctx context.Context, rows [][]any, ops []EventOp) ([][]any, []EventOp, func(bool), error) {
+	keys := make([]string, len(rows))
+	ok := make([]bool, len(rows))
+	for idx, row := range rows {
+		if ops == nil || ops[idx] == EventInsert {
+			keys[idx], ok[idx] = dedupKey(row[i.dedupIdx])
+		}
+	}
+	keep, done, err := i.dedup.Claim(ctx, keys, ok)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to deduplicate events")
+	}
+
+	var (
+		kept    = rows[:0]
+		keptOps []EventOp
+	)
+	if ops != nil {
+		keptOps = ops[:0]
+	}
+	for idx, row := range rows {
+		if !keep[idx] {
+			continue
+		}
+		kept = append(kept, row)
+		if ops != nil {
+			keptOps = append(keptOps, ops[idx])
+		}
+	}
+	if dropped := len(rows) - len(kept); dropped > 0 {
+		DedupDroppedEvents.WithLabelValues(i.dedup.table).Add(float64(dropped))
+	}
+	return kept, keptOps, done, nil
+}
+
 func (i *EventHandler) Close() {
 	i.bio.Close()
 }
 
+// Drain stops the handler once the events it accepted are inserted, see BulkInsertOperator.Drain.
+func (i *EventHandler) Drain() {
+	i.bio.Drain()
+}
+
+func (i *EventHandler) acquire() {
+	i.inUse.Add(1)
+	i.lastUsed.Store(time.Now().UnixNano())
+}
+
+func (i *EventHandler) release() {
+	i.lastUsed.Store(time.Now().UnixNano())
+	i.inUse.Add(-1)
+}
+
+// idle reports whether no ingest has used the handler for d. It must be called with the write lock of the event
+// service held, so that no ingest can acquire the handler meanwhile.
+func (i *EventHandler) idle(now time.Time, d time.Duration) bool {
+	return i.inUse.Load() == 0 && now.Sub(time.Unix(0, i.lastUsed.Load())) >= d
+}
+
+// defaultIdleTimeout is how long the handler of a table is kept without ingests by default.
+const defaultIdleTimeout = 10 * time.Minute
+
+var ActiveEventHandlers = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "events-api_rw_active_event_handlers",
+		Help: "The number of tables with a bulk insert operator, which is created on the first ingest and closed when idle",
+	},
+)
+
+// EventService routes events to the tables they are ingested into. It only keeps the metadata of ingestible tables,
+// the handler of a table and its bulk insert operator are created on the first ingest and closed when idle.
 type EventService struct {
-	handlers map[string]*EventHandler
-	mu       sync.RWMutex
-	cm       *closer.CloserManager
+	relations map[string]Relation
+	handlers  map[string]*EventHandler
+	disabled  map[string]bool
+	mu        sync.RWMutex
+	cm        *closer.CloserManager
+
+	// dedup are the dedup sets by canonical table name, they outlive the handlers
+	dedup map[string]*DedupSet
+
+	// enrichments are the configured enrichments by canonical table name
+	enrichments map[string]Enrichment
+	geo         *enrich.GeoIP
+
+	idleTimeout time.Duration
+	filter      *TableFilter
+	searchPath  SearchPath
+	watcher     *Watcher
+	bim         *BulkInsertManager
+	log         *zap.Logger
+}
 
-	bim *BulkInsertManager
-	log *zap.Logger
+// IngestionStatus tells whether events can be ingested into a table.
+type IngestionStatus struct {
+	// Table is the canonical name of the table.
+	Table string
+
+	Enabled bool
+
+	// Excluded is true if the configuration excludes the table, it cannot be enabled at runtime.
+	Excluded bool
 }
 
-func NewEventService(gctx *gctx.GlobalContext, rw *RisingWave, log *zap.Logger, bim *BulkInsertManager, cm *closer.CloserManager) (*EventService, error) {
+func NewEventService(cfg *config.Config, searchPath SearchPath, watcher *Watcher, gctx *gctx.GlobalContext, log *zap.Logger, bim *BulkInsertManager, cm *closer.CloserManager, geo *enrich.GeoIP) (*EventService, error) {
+	filter, err := NewTableFilter(cfg, searchPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ingest config")
+	}
+	dedup, err := newDedupSets(cfg, searchPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ingest config")
+	}
+	enrichments, err := enrichRules(cfg, searchPath, geo)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ingest config")
+	}
+
 	es := &EventService{
-		handlers: make(map[string]*EventHandler),
-		bim:      bim,
-		log:      log.Named("event_service"),
-		cm:       cm,
+		relations:   make(map[string]Relation),
+		handlers:    make(map[string]*EventHandler),
+		disabled:    make(map[string]bool),
+		dedup:       dedup,
+		enrichments: enrichments,
+		geo:         geo,
+		idleTimeout: defaultIdleTimeout,
+		filter:      filter,
+		searchPath:  searchPath,
+		watcher:     watcher,
+		bim:         bim,
+		log:         log.Named("event_service"),
+		cm:          cm,
+	}
+	if cfg.Ingest.IdleTimeout != "" {
+		d, err := time.ParseDuration(cfg.Ingest.IdleTimeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ingest idle timeout")
+		}
+		if d <= 0 {
+			return nil, errors.Errorf("invalid ingest idle timeout %s", cfg.Ingest.IdleTimeout)
+		}
+		es.idleTimeout = d
 	}
 
 	cm.Register(func(ctx context.Context) error {
-		for _, handler := range es.handlers {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		for key, handler := range es.handlers {
 			handler.Close()
+			delete(es.handlers, key)
 		}
+		ActiveEventHandlers.Set(0)
 		return nil
 	})
 
-	watcher := NewWatcher(rw, gctx, log, es.onRelatioonUpdate, es.onRelationDelete)
-	if err := watcher.UpdateCache(gctx.Context()); err != nil { // initial cache update
-		return nil, errors.Wrap(err, "failed to perform initial cache update")
-	}
-	go watcher.Start()
+	watcher.AddListener(es.onRelationUpdate, es.onRelationDelete)
+
+	go es.runEviction(gctx.Context())
 
 	return es, nil
 }
 
-func (s *EventService) IngestEvent(ctx context.Context, name string, raw []byte) error {
-	key := name
-	if !strings.ContainsAny(name, ".") {
-		key = "public." + name
+// lookup returns the canonical name of an ingestible table, unqualified names are looked up in the schemas of the
+// search path in order. It must be called with the lock held.
+func (s *EventService) lookup(name string) (string, bool) {
+	for _, key := range s.searchPath.CandidateNames(name) {
+		if _, ok := s.relations[key]; ok {
+			return key, true
+		}
 	}
+	return "", false
+}
 
+// acquire returns the handler of an ingestible table, creating it on the first ingest. The handler must be released
+// once the ingest is done.
+func (s *EventService) acquire(name string) (*EventHandler, error) {
 	s.mu.RLock()
-	handler, exist := s.handlers[key]
+	key, ok := s.lookup(name)
+	if !ok {
+		s.mu.RUnlock()
+		return nil, s.notIngestible(name)
+	}
+	if handler, ok := s.handlers[key]; ok {
+		handler.acquire()
+		s.mu.RUnlock()
+		return handler, nil
+	}
 	s.mu.RUnlock()
 
-	if !exist {
-		return errors.Errorf("no handler for relation %s", key)
+	s.mu.Lock()
+	handler, err := s.create(key)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if handler == nil { // dropped or disabled meanwhile
+		return nil, s.notIngestible(name)
 	}
+	return handler, nil
+}
 
-	if err := handler.Ingest(ctx, bytes.Split(raw, []byte("\n"))); err != nil {
-		return errors.Wrap(err, "failed to ingest event")
+// create returns the acquired handler of a table, creating it if needed, or nil if the table is not ingestible. It must
+// be called with the write lock held.
+func (s *EventService) create(key string) (*EventHandler, error) {
+	relation, ok := s.relations[key]
+	if !ok {
+		return nil, nil
+	}
+	handler, ok := s.handlers[key]
+	if !ok {
+		s.log.Info("create event handler for relation", zap.String("relation", key))
+		var err error
+		dedup := s.dedup[key]
+		if dedup != nil && !slices.ContainsFunc(relation.Columns, func(c Column) bool { return c.Name == dedup.column && Ingestible(c) }) {
+			s.log.Warn("dedup column not found, events are not deduplicated", zap.String("relation", key), zap.String("column", dedup.column))
+		}
+		handler, err = NewEventHandler(pgx.Identifier{relation.Schema, relation.Name}.Sanitize(), relation.Columns, s.bim, dedup, s.enrichment(key, relation), s.geo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create event handler")
+		}
+		s.handlers[key] = handler
+		ActiveEventHandlers.Set(float64(len(s.handlers)))
 	}
+	handler.acquire()
+	return handler, nil
+}
 
-	return nil
+// notIngestible returns the error of ingesting into a table that is not ingestible. It looks the table up in the
+// watcher, which calls the listeners of the service, so it must not be called with the lock of the service held.
+func (s *EventService) notIngestible(name string) error {
+	if r, ok := s.watcher.Relation(name); ok {
+		return errors.Wrapf(ErrIngestionDisabled, "ingestion into %s is disabled", r.QualifiedName())
+	}
+	return errors.Wrapf(ErrRelationNotFound, "no table %s to ingest into", s.searchPath.QualifiedName(name))
 }
 
-func (s *EventService) onRelatioonUpdate(relation Relation) error {
-	var (
-		oldHandler *EventHandler
-		ok         bool
-	)
+// QualifiedName returns the canonical name of the table events with the given table name are ingested into, names of
+// unknown tables are qualified with the first schema of the search path.
+func (s *EventService) QualifiedName(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.lookup(name); ok {
+		return key
+	}
+	return s.searchPath.QualifiedName(name)
+}
+
+// EnrichedColumns returns the columns of a table that are filled by the server instead of the events.
+func (s *EventService) EnrichedColumns(r Relation) map[string]EnrichSource {
+	return enrichedColumns(r.Columns, s.enrichments[r.QualifiedName()].Columns)
+}
+
+// enrichment returns the enrichment of a table, with the columns following the naming convention. It warns of the
+// columns whose values cannot be known, they are NULL.
+func (s *EventService) enrichment(key string, r Relation) Enrichment {
+	e := s.enrichments[key]
+	e.Columns = enrichedColumns(r.Columns, e.Columns)
+	for col, src := range e.Columns {
+		if !enrichAvailable(src, s.geo) {
+			s.log.Warn("no geoip database for enriched column", zap.String("relation", key), zap.String("column", col), zap.String("source", string(src)))
+		}
+	}
+	for _, col := range []string{e.UserAgentColumn, e.IPColumn} {
+		if col != "" && !slices.ContainsFunc(r.Columns, func(c Column) bool { return c.Name == col }) {
+			s.log.Warn("enrichment input column not found, the columns derived from it are NULL", zap.String("relation", key), zap.String("column", col))
+		}
+	}
+	return e
+}
+
+// Ingestible reports whether events can be ingested into the table.
+func (s *EventService) Ingestible(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.lookup(name)
+	return ok
+}
+
+// IngestEvent writes the events of raw, one JSON object per line. op is the operation of the events that do not set
+// one with EventOpField, meta describes the request for the enriched columns.
+func (s *EventService) IngestEvent(ctx context.Context, name string, op EventOp, raw []byte, meta *RequestMeta) error {
+	lines := bytes.Split(raw, []byte("\n"))
+	for attempt := 0; ; attempt++ {
+		handler, err := s.acquire(name)
+		if err != nil {
+			return err
+		}
+		err = handler.Ingest(ctx, lines, op, meta)
+		handler.release()
+
+		// the handler was swapped for one with the new columns of the table in between, which takes the events
+		if errors.Is(err, ErrBulkInsertClosed) && attempt == 0 {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to ingest event")
+		}
+		return nil
+	}
+}
+
+// runEviction closes the handlers that have not been used for the idle timeout.
+func (s *EventService) runEviction(ctx context.Context) {
+	ticker := time.NewTicker(max(s.idleTimeout/4, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.evictIdle(now)
+		}
+	}
+}
+
+func (s *EventService) evictIdle(now time.Time) {
+	var idle []*EventHandler
 
 	s.mu.Lock()
-	defer func() {
-		s.mu.Unlock()
-		if oldHandler != nil && ok {
-			oldHandler.Close()
+	for key, handler := range s.handlers {
+		if handler.idle(now, s.idleTimeout) {
+			s.log.Info("close idle event handler", zap.String("relation", key))
+			delete(s.handlers, key)
+			idle = append(idle, handler)
 		}
-	}()
+	}
+	ActiveEventHandlers.Set(float64(len(s.handlers)))
+	s.mu.Unlock()
 
-	s.log.Info("create event handler for relation", zap.Any("relation", relation))
+	for _, handler := range idle {
+		handler.Drain()
+	}
+}
 
-	handler, err := NewEventHandler(relation.Schema+"."+relation.Name, relation.Columns, s.bim)
-	if err != nil {
-		return errors.Wrap(err, "failed to create event handler")
+// Ingestion returns whether events can be ingested into the table.
+func (s *EventService) Ingestion(name string) (IngestionStatus, error) {
+	r, ok := s.watcher.Relation(name)
+	if !ok {
+		return IngestionStatus{}, errors.Wrapf(ErrRelationNotFound, "no table %s", s.searchPath.QualifiedName(name))
+	}
+	key := r.QualifiedName()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	excluded := !s.filter.Allows(r.Schema, r.Name)
+	return IngestionStatus{
+		Table:    key,
+		Enabled:  !excluded && !s.disabled[key],
+		Excluded: excluded,
+	}, nil
+}
+
+// SetIngestion enables or disables ingestion into a table until the server restarts. Disabling a table drains its
+// handler, events that were accepted before are still inserted.
+func (s *EventService) SetIngestion(name string, enabled bool) (IngestionStatus, error) {
+	r, ok := s.watcher.Relation(name)
+	if !ok {
+		return IngestionStatus{}, errors.Wrapf(ErrRelationNotFound, "no table %s", s.searchPath.QualifiedName(name))
+	}
+	key := r.QualifiedName()
+	if enabled && !s.filter.Allows(r.Schema, r.Name) {
+		return IngestionStatus{}, errors.Wrapf(ErrIngestionDisabled, "%s is excluded by the configuration", key)
+	}
+
+	s.mu.Lock()
+	handler, ok := s.handlers[key]
+	if enabled {
+		delete(s.disabled, key)
+		s.relations[key] = r
+		ok = false
+	} else {
+		s.disabled[key] = true
+		delete(s.relations, key)
+		delete(s.handlers, key)
+	}
+	ActiveEventHandlers.Set(float64(len(s.handlers)))
+	s.mu.Unlock()
+
+	if ok {
+		handler.Drain()
+	}
+	s.log.Info("set ingestion of relation", zap.String("relation", key), zap.Bool("enabled", enabled))
+	return IngestionStatus{Table: key, Enabled: enabled}, nil
+}
+
+// onRelationUpdate records the metadata of an ingestible table. The handler of a table whose definition changed is
+// drained, so the events it accepted are inserted with the old columns while the next ingest creates a handler with
+// the new ones.
+func (s *EventService) onRelationUpdate(relation Relation) error {
+	key := relation.QualifiedName()
+
+	s.mu.Lock()
+	handler, ok := s.handlers[key]
+	delete(s.handlers, key)
+	if s.filter.Allows(relation.Schema, relation.Name) && !s.disabled[key] {
+		s.relations[key] = relation
+	} else {
+		delete(s.relations, key)
+	}
+	ActiveEventHandlers.Set(float64(len(s.handlers)))
+	s.mu.Unlock()
+
+	if ok {
+		handler.Drain()
 	}
-	oldHandler, ok = s.handlers[relation.Schema+"."+relation.Name]
-	s.handlers[relation.Schema+"."+relation.Name] = handler
 	return nil
 }
 
 func (s *EventService) onRelationDelete(name string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	handler, ok := s.handlers[name]
+	delete(s.handlers, name)
+	delete(s.relations, name)
+	ActiveEventHandlers.Set(float64(len(s.handlers)))
+	s.mu.Unlock()
+
 	if ok {
 		handler.Close()
-		delete(s.handlers, name)
 	}
 	return nil
 }
@@ -0,0 +1,135 @@
+package rw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/risingwavelabs/events-api/pkg/closer"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/enrich"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventHandlerIdle(t *testing.T) {
+	var h EventHandler
+	h.acquire()
+	require.False(t, h.idle(time.Now().Add(time.Hour), time.Minute), "in use")
+
+	h.release()
+	require.False(t, h.idle(time.Now(), time.Minute))
+	require.True(t, h.idle(time.Now().Add(time.Minute), time.Minute))
+}
+
+func TestEventParserOps(t *testing.T) {
+	p := NewEventParser([]Column{{Name: "id", Type: "bigint", IsPrimaryKey: true}, {Name: "name", Type: "character varying"}}, nil)
+
+	rows, ops, err := p.Parse([][]byte{[]byte(`{"id": 1, "name": "a"}`), []byte(`{"id": 2}`)}, EventInsert, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Nil(t, ops)
+
+	rows, ops, err = p.Parse([][]byte{[]byte(`{"id": 1, "name": "a"}`), []byte(`{"id": 2, "_op": "delete"}`)}, EventUpsert, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, []EventOp{EventUpsert, EventDelete}, ops)
+
+	_, _, err = p.Parse([][]byte{[]byte(`{"name": "a", "_op": "delete"}`)}, EventInsert, nil)
+	require.Error(t, err)
+	_, _, err = p.Parse([][]byte{[]byte(`{"id": 1, "_op": "merge"}`)}, EventInsert, nil)
+	require.ErrorIs(t, err, ErrInvalidEvent)
+
+	noPK := NewEventParser([]Column{{Name: "id", Type: "bigint"}, {Name: "_op", Type: "character varying"}}, nil)
+	_, _, err = noPK.Parse([][]byte{[]byte(`{"id": 1}`)}, EventDelete, nil)
+	require.Error(t, err)
+	rows, ops, err = noPK.Parse([][]byte{[]byte(`{"id": 1, "_op": "delete"}`)}, EventInsert, nil)
+	require.NoError(t, err, "_op is a column of the table")
+	require.Equal(t, "delete", rows[0][1])
+	require.Nil(t, ops)
+}
+
+func TestEventParserEnrich(t *testing.T) {
+	cols := []Column{
+		{Name: "id", Type: "bigint"},
+		{Name: "ip", Type: "character varying"},
+		{Name: "_ingest_received_at", Type: "timestamp"},
+		{Name: "_ingest_user_agent", Type: "character varying"},
+	}
+	enriched := enrichedColumns(cols, map[string]EnrichSource{"ip": EnrichClientIP})
+	require.Equal(t, map[string]EnrichSource{"ip": EnrichClientIP, "_ingest_received_at": EnrichReceivedAt, "_ingest_user_agent": EnrichUserAgent}, enriched)
+
+	p := NewEventParser(cols, enriched)
+	meta := &RequestMeta{ReceivedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("CET", 3600)), ClientIP: "203.0.113.7"}
+	rows, _, err := p.Parse([][]byte{[]byte(`{"id": 1, "ip": "127.0.0.1", "_ingest_user_agent": "spoofed"}`)}, EventInsert, meta)
+	require.NoError(t, err)
+	require.Equal(t, []any{float64(1), "203.0.113.7", "2024-01-15 09:30:00", nil}, rows[0])
+}
+
+func TestDeriver(t *testing.T) {
+	cols := []Column{
+		{Name: "ua", Type: "character varying"},
+		{Name: "browser", Type: "character varying"},
+		{Name: "_ingest_device", Type: "character varying"},
+		{Name: "asn", Type: "bigint"},
+	}
+	e := Enrichment{
+		Columns:         enrichedColumns(cols, map[string]EnrichSource{"browser": EnrichBrowser, "asn": EnrichASN}),
+		UserAgentColumn: "ua",
+	}
+	p := NewEventParser(cols, e.Columns)
+	d := newDeriver(p, e, nil)
+	require.NotNil(t, d)
+
+	rows, _, err := p.Parse([][]byte{
+		[]byte(`{"ua": "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "browser": "spoofed"}`),
+		[]byte(`{}`),
+	}, EventInsert, &RequestMeta{UserAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"})
+	require.NoError(t, err)
+	d.derive(rows, nil)
+	require.Equal(t, []any{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox", "desktop", nil}, rows[0])
+	require.Equal(t, []any{nil, nil, nil, nil}, rows[1], "the column is used, not the header")
+
+	require.Nil(t, newDeriver(p, Enrichment{Columns: map[string]EnrichSource{"ua": EnrichUserAgent}}, nil))
+}
+
+func TestDeriverGeoIP(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GeoIP.CityDB = "../enrich/testdata/GeoIP2-City-Test.mmdb"
+	cfg.GeoIP.ASNDB = "../enrich/testdata/GeoLite2-ASN-Test.mmdb"
+	geo, err := enrich.NewGeoIP(cfg, zap.NewNop(), closer.NewCloserManager(zap.NewNop()))
+	require.NoError(t, err)
+	defer geo.Close()
+
+	cols := []Column{
+		{Name: "ip", Type: "character varying"},
+		{Name: "country", Type: "character varying"},
+		{Name: "region", Type: "character varying"},
+		{Name: "city", Type: "character varying"},
+		{Name: "asn", Type: "bigint"},
+		{Name: "asn_text", Type: "character varying"},
+		{Name: "as_org", Type: "character varying"},
+	}
+	e := Enrichment{
+		Columns: enrichedColumns(cols, map[string]EnrichSource{
+			"country": EnrichCountry, "region": EnrichRegion, "city": EnrichCity,
+			"asn": EnrichASN, "asn_text": EnrichASN, "as_org": EnrichASOrg,
+		}),
+		IPColumn: "ip",
+	}
+	p := NewEventParser(cols, e.Columns)
+	d := newDeriver(p, e, geo)
+	require.NotNil(t, d)
+
+	rows, _, err := p.Parse([][]byte{
+		[]byte(`{"ip": "89.160.20.112", "country": "spoofed"}`),
+		[]byte(`{"ip": "81.2.69.142"}`),
+		[]byte(`{"ip": "127.0.0.1"}`),
+		[]byte(`{}`),
+	}, EventInsert, &RequestMeta{ClientIP: "89.160.20.112"})
+	require.NoError(t, err)
+	d.derive(rows, nil)
+	require.Equal(t, []any{"89.160.20.112", "SE", "Östergötland County", "Linköping", int64(29518), "29518", "Bredband2 AB"}, rows[0])
+	require.Equal(t, []any{"81.2.69.142", "GB", "England", "London", nil, nil, nil}, rows[1], "unknown fields are NULL")
+	require.Equal(t, []any{"127.0.0.1", nil, nil, nil, nil, nil, nil}, rows[2])
+	require.Equal(t, []any{nil, nil, nil, nil, nil, nil, nil}, rows[3], "the column is used, not the client IP")
+}
@@ -0,0 +1,103 @@
+package rw
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+)
+
+// systemSchemas are never ingested into, whatever the configuration.
+var systemSchemas = map[string]bool{
+	"pg_catalog":         true,
+	"information_schema": true,
+	"rw_catalog":         true,
+}
+
+// tablePattern matches tables by glob patterns of their schema and name, as in path.Match.
+type tablePattern struct {
+	schema string
+	name   string
+}
+
+// parseTablePattern parses "schema.name" patterns such as "analytics.*". A pattern without a dot matches the name in
+// any schema.
+func parseTablePattern(p string) (tablePattern, error) {
+	ret := tablePattern{schema: "*", name: p}
+	if schema, name, ok := strings.Cut(p, "."); ok {
+		ret = tablePattern{schema: schema, name: name}
+	}
+	if ret.schema == "" || ret.name == "" {
+		return ret, errors.Errorf("invalid table pattern %q", p)
+	}
+	for _, glob := range []string{ret.schema, ret.name} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return ret, errors.Wrapf(err, "invalid table pattern %q", p)
+		}
+	}
+	return ret, nil
+}
+
+func (p tablePattern) match(schema, name string) bool {
+	ok, _ := path.Match(p.schema, schema)
+	if !ok {
+		return false
+	}
+	ok, _ = path.Match(p.name, name)
+	return ok
+}
+
+// TableFilter decides which tables events may be ingested into according to the configuration.
+type TableFilter struct {
+	include  []tablePattern
+	exclude  []tablePattern
+	internal map[string]bool
+}
+
+func NewTableFilter(cfg *config.Config, searchPath SearchPath) (*TableFilter, error) {
+	f := &TableFilter{internal: make(map[string]bool)}
+	for _, p := range cfg.Ingest.Include {
+		tp, err := parseTablePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, tp)
+	}
+	for _, p := range cfg.Ingest.Exclude {
+		tp, err := parseTablePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, tp)
+	}
+	// the tables the server keeps its own state in are not for clients to write
+	for _, table := range []string{cfg.SavedQueries.Table, cfg.RateLimit.PersistTable} {
+		if table != "" {
+			f.internal[searchPath.QualifiedName(table)] = true
+		}
+	}
+	return f, nil
+}
+
+// Allows reports whether events can be ingested into the table. Exclusions take precedence over inclusions, and all
+// tables are included if there is no include pattern.
+func (f *TableFilter) Allows(schema, name string) bool {
+	if systemSchemas[schema] || f.internal[FormatName(schema, name)] {
+		return false
+	}
+	for _, p := range f.exclude {
+		if p.match(schema, name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if p.match(schema, name) {
+			return true
+		}
+	}
+	return false
+}
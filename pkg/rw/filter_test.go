@@ -0,0 +1,36 @@
+package rw
+
+import (
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableFilter(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ingest.Include = []string{"analytics.*", "events_*"}
+	cfg.Ingest.Exclude = []string{"analytics.tmp_*"}
+	cfg.SavedQueries.Table = "analytics.saved_queries"
+
+	f, err := NewTableFilter(cfg, SearchPath{"public"})
+	require.NoError(t, err)
+	require.True(t, f.Allows("analytics", "clicks"))
+	require.True(t, f.Allows("public", "events_web"))
+	require.False(t, f.Allows("public", "clicks"))
+	require.False(t, f.Allows("analytics", "tmp_clicks"))
+	require.False(t, f.Allows("analytics", "saved_queries"))
+	require.False(t, f.Allows("rw_catalog", "events_x"))
+
+	f, err = NewTableFilter(&config.Config{}, SearchPath{"public"})
+	require.NoError(t, err)
+	require.True(t, f.Allows("public", "clicks"))
+	require.False(t, f.Allows("pg_catalog", "pg_class"))
+
+	for _, p := range []string{"", "analytics.", ".x", "a.[b"} {
+		cfg := &config.Config{}
+		cfg.Ingest.Include = []string{p}
+		_, err := NewTableFilter(cfg, SearchPath{"public"})
+		require.Error(t, err, p)
+	}
+}
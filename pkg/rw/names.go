@@ -0,0 +1,95 @@
+package rw
+
+import (
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+)
+
+const defaultSchema = "public"
+
+// SearchPath are the schemas unqualified relation names are resolved against, in order.
+type SearchPath []string
+
+// NewSearchPath returns the search path of the configuration, ["public"] if it is not set.
+func NewSearchPath(cfg *config.Config) (SearchPath, error) {
+	if len(cfg.Catalog.SearchPath) == 0 {
+		return SearchPath{defaultSchema}, nil
+	}
+	for _, s := range cfg.Catalog.SearchPath {
+		if s == "" {
+			return nil, errors.New("invalid catalog search path, schema names must not be empty")
+		}
+	}
+	return SearchPath(slices.Clone(cfg.Catalog.SearchPath)), nil
+}
+
+// ParseName splits a possibly schema-qualified relation name. Unquoted identifiers are folded to lower case, quoted
+// ones are kept as written, the schema is empty if the name is not qualified.
+func ParseName(name string) (string, string, error) {
+	parts, err := statement.ParseIdentifier(name)
+	if err != nil {
+		return "", "", err
+	}
+	switch len(parts) {
+	case 1:
+		return "", parts[0], nil
+	case 2:
+		return parts[0], parts[1], nil
+	}
+	return "", "", errors.Wrapf(statement.ErrInvalidName, "%q has more than two parts", name)
+}
+
+// FormatName returns the canonical name of a relation, which is the key relations are known by. Both parts are
+// quoted as by statement.QuoteIdent, so that names of the same relation compare equal however they were written.
+func FormatName(schema, relation string) string {
+	return statement.QuoteIdent(schema) + "." + statement.QuoteIdent(relation)
+}
+
+// QualifiedName returns the canonical name of a relation. Unqualified names belong to the first schema of the search
+// path, names that do not parse are returned as is and do not match any relation.
+func (sp SearchPath) QualifiedName(name string) string {
+	schema, relation, err := ParseName(name)
+	if err != nil {
+		return name
+	}
+	if schema == "" {
+		schema = sp[0]
+	}
+	return FormatName(schema, relation)
+}
+
+// CandidateNames returns the canonical names a relation name may refer to, one per schema of the search path if the
+// name is not qualified.
+func (sp SearchPath) CandidateNames(name string) []string {
+	schema, relation, err := ParseName(name)
+	if err != nil {
+		return []string{name}
+	}
+	if schema != "" {
+		return []string{FormatName(schema, relation)}
+	}
+	ret := make([]string, len(sp))
+	for i, s := range sp {
+		ret[i] = FormatName(s, relation)
+	}
+	return ret
+}
+
+// Identifier returns the SQL identifier of a possibly schema-qualified name, with all parts quoted. Unqualified
+// names are left unqualified, so that RisingWave resolves them by its search_path.
+func Identifier(name string) (string, error) {
+	parts, err := statement.ParseIdentifier(name)
+	if err != nil {
+		return "", err
+	}
+	return pgx.Identifier(parts).Sanitize(), nil
+}
+
+// QualifiedName returns the canonical name of the relation.
+func (r Relation) QualifiedName() string {
+	return FormatName(r.Schema, r.Name)
+}
@@ -0,0 +1,55 @@
+package rw
+
+import (
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualifiedName(t *testing.T) {
+	sp := SearchPath{"public"}
+	require.Equal(t, "public.clicks", sp.QualifiedName("Clicks"))
+	require.Equal(t, "analytics.clicks", sp.QualifiedName("analytics.clicks"))
+	require.Equal(t, `analytics."Click Events"`, sp.QualifiedName(`Analytics."Click Events"`))
+	require.Equal(t, `"my.schema"."a.b"`, sp.QualifiedName(`"my.schema"."a.b"`))
+	require.Equal(t, "a b", sp.QualifiedName("a b"))
+	require.Equal(t, `"a.b".c`, Relation{Schema: "a.b", Name: "c"}.QualifiedName())
+}
+
+func TestCandidateNames(t *testing.T) {
+	sp := SearchPath{"analytics", "public"}
+	require.Equal(t, []string{"analytics.clicks", "public.clicks"}, sp.CandidateNames("clicks"))
+	require.Equal(t, []string{"public.clicks"}, sp.CandidateNames("public.clicks"))
+	require.Equal(t, "analytics.clicks", sp.QualifiedName("clicks"))
+}
+
+func TestNewSearchPath(t *testing.T) {
+	sp, err := NewSearchPath(&config.Config{})
+	require.NoError(t, err)
+	require.Equal(t, SearchPath{"public"}, sp)
+
+	cfg := &config.Config{}
+	cfg.Catalog.SearchPath = []string{"analytics", "public"}
+	sp, err = NewSearchPath(cfg)
+	require.NoError(t, err)
+	require.Equal(t, SearchPath{"analytics", "public"}, sp)
+
+	cfg.Catalog.SearchPath = []string{"analytics", ""}
+	_, err = NewSearchPath(cfg)
+	require.Error(t, err)
+}
+
+func TestIdentifier(t *testing.T) {
+	ident, err := Identifier(`Analytics."Click Events"`)
+	require.NoError(t, err)
+	require.Equal(t, `"analytics"."Click Events"`, ident)
+
+	_, err = Identifier("a.b.c.d e")
+	require.Error(t, err)
+}
+
+func TestBuildPrepareSQL(t *testing.T) {
+	sql := _buildPrepareSQL(`"analytics"."Click Events"`, []Column{{Name: "id"}, {Name: "Page URL"}, {Name: `say "hi"`}})
+	require.Equal(t, `INSERT INTO "analytics"."Click Events" ("id", "Page URL", "say ""hi""") VALUES `, sql)
+}
@@ -0,0 +1,123 @@
+package rw
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConvertParams converts query parameters decoded from JSON with json.Decoder.UseNumber to values pgx can encode.
+// Integers become int64, other numbers float64, as do integers beyond the range of int64, flat homogeneous arrays
+// typed slices, where integers are promoted to float64 if there are other numbers, and objects their JSON text.
+func ConvertParams(params []any) ([]any, error) {
+	ret := make([]any, len(params))
+	for i, p := range params {
+		v, err := convertParam(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid parameter $%d", i+1)
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+func convertParam(p any) (any, error) {
+	switch v := p.(type) {
+	case nil, string, bool:
+		return v, nil
+	case json.Number:
+		return convertNumber(v)
+	case map[string]any:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	case []any:
+		return convertArray(v)
+	}
+	return nil, errors.Errorf("unsupported parameter type %T", p)
+}
+
+func convertNumber(n json.Number) (any, error) {
+	if !strings.ContainsAny(n.String(), ".eE") {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	return n.Float64()
+}
+
+func convertArray(arr []any) (any, error) {
+	var (
+		ints    []int64
+		floats  []float64
+		strs    []string
+		bools   []bool
+		kind    string
+		setKind = func(k string) error {
+			if kind != "" && kind != k {
+				return errors.Errorf("array elements must have the same type, got %s and %s", kind, k)
+			}
+			kind = k
+			return nil
+		}
+	)
+
+	for _, item := range arr {
+		v, err := convertParam(item)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case int64:
+			if kind == "float" {
+				floats = append(floats, float64(v))
+				continue
+			}
+			if err := setKind("int"); err != nil {
+				return nil, err
+			}
+			ints = append(ints, v)
+		case float64:
+			if kind == "int" {
+				// promote the integers seen so far
+				for _, i := range ints {
+					floats = append(floats, float64(i))
+				}
+				ints, kind = nil, "float"
+			}
+			if err := setKind("float"); err != nil {
+				return nil, err
+			}
+			floats = append(floats, v)
+		case string:
+			if err := setKind("string"); err != nil {
+				return nil, err
+			}
+			strs = append(strs, v)
+		case bool:
+			if err := setKind("bool"); err != nil {
+				return nil, err
+			}
+			bools = append(bools, v)
+		case nil:
+			return nil, errors.New("null array elements are not supported")
+		default:
+			return nil, errors.New("nested arrays are not supported")
+		}
+	}
+
+	switch kind {
+	case "int":
+		return ints, nil
+	case "float":
+		return floats, nil
+	case "string":
+		return strs, nil
+	case "bool":
+		return bools, nil
+	}
+	return []string{}, nil
+}
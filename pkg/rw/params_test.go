@@ -0,0 +1,67 @@
+package rw
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertParams(t *testing.T) {
+	testCases := []struct {
+		name     string
+		json     string
+		expected any
+		err      string
+	}{
+		{"int", `42`, int64(42), ""},
+		{"negative int", `-7`, int64(-7), ""},
+		{"float", `1.5`, 1.5, ""},
+		{"integral float", `1.0`, 1.0, ""},
+		{"exponent", `1e3`, 1000.0, ""},
+		{"max int64", `9223372036854775807`, int64(9223372036854775807), ""},
+		{"int beyond int64", `9223372036854775808`, 9223372036854775808.0, ""},
+		{"string", `"hello"`, "hello", ""},
+		{"numeric string", `"42"`, "42", ""},
+		{"bool", `true`, true, ""},
+		{"null", `null`, nil, ""},
+		{"object", `{"b": [1, 2], "a": {"c": null}}`, `{"a":{"c":null},"b":[1,2]}`, ""},
+		{"int array", `[1, 2, 3]`, []int64{1, 2, 3}, ""},
+		{"float array", `[0.5, 1.5]`, []float64{0.5, 1.5}, ""},
+		{"ints promoted to floats", `[1, 2.5, 3]`, []float64{1, 2.5, 3}, ""},
+		{"floats then ints", `[2.5, 1]`, []float64{2.5, 1}, ""},
+		{"string array", `["a", "b"]`, []string{"a", "b"}, ""},
+		{"bool array", `[true, false]`, []bool{true, false}, ""},
+		{"empty array", `[]`, []string{}, ""},
+		{"object array", `[{"a": 1}, {"b": 2}]`, []string{`{"a":1}`, `{"b":2}`}, ""},
+		{"mixed array", `[1, "a"]`, nil, "same type"},
+		{"strings and bools", `["a", true]`, nil, "same type"},
+		{"null element", `[1, null]`, nil, "null array elements"},
+		{"nested array", `[[1, 2], [3]]`, nil, "nested arrays"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec := json.NewDecoder(bytes.NewReader([]byte(`[` + tc.json + `]`)))
+			dec.UseNumber()
+			var params []any
+			require.NoError(t, dec.Decode(&params))
+
+			ret, err := ConvertParams(params)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				require.ErrorContains(t, err, "$1")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []any{tc.expected}, ret)
+		})
+	}
+}
+
+func TestConvertParamsUnsupported(t *testing.T) {
+	// numbers must be decoded as json.Number
+	_, err := ConvertParams([]any{"a", 1.5})
+	require.ErrorContains(t, err, "invalid parameter $2")
+}
@@ -3,9 +3,10 @@ package rw
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/cloudcarver/anclax/pkg/utils"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,12 +18,11 @@ import (
 	"go.uber.org/zap"
 )
 
-var ErrQueryFailed = errors.New("query failed")
-
 type RisingWave struct {
-	pool      *pgxpool.Pool
-	dsn       string
-	globalCtx *gctx.GlobalContext
+	pool       *pgxpool.Pool
+	dsn        string
+	searchPath SearchPath
+	globalCtx  *gctx.GlobalContext
 }
 
 func parse(cfg *config.Rw) string {
@@ -39,7 +39,7 @@ func parse(cfg *config.Rw) string {
 	)
 }
 
-func NewRisingWave(cfg *config.Config, globalCtx *gctx.GlobalContext, cm *closer.CloserManager, log *zap.Logger) (*RisingWave, error) {
+func NewRisingWave(cfg *config.Config, searchPath SearchPath, globalCtx *gctx.GlobalContext, cm *closer.CloserManager, log *zap.Logger) (*RisingWave, error) {
 	if cfg.Rw == nil {
 		return nil, errors.New("risingwave config is nil")
 	}
@@ -54,7 +54,27 @@ func NewRisingWave(cfg *config.Config, globalCtx *gctx.GlobalContext, cm *closer
 		return nil, errors.Wrap(err, "failed to parse dsn")
 	}
 
+	// the search_path of RisingWave is only changed if one is configured
+	setSearchPath := ""
+	if len(cfg.Catalog.SearchPath) > 0 {
+		schemas := make([]string, len(searchPath))
+		for i, s := range searchPath {
+			schemas[i] = pgx.Identifier{s}.Sanitize()
+		}
+		setSearchPath = "SET search_path TO " + strings.Join(schemas, ", ")
+	}
+
 	pgxCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	pgxCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		registerTypes(conn.TypeMap())
+		if setSearchPath != "" {
+			// unqualified names in SQL resolve the same way as the table names of the API
+			if _, err := conn.Exec(ctx, setSearchPath); err != nil {
+				return errors.Wrap(err, "failed to set search_path")
+			}
+		}
+		return nil
+	}
 	pgxCfg.MaxConns = 1000
 	pgxCfg.MinConns = 10
 
@@ -85,9 +105,10 @@ func NewRisingWave(cfg *config.Config, globalCtx *gctx.GlobalContext, cm *closer
 	}
 
 	rw := &RisingWave{
-		pool:      pool,
-		globalCtx: globalCtx,
-		dsn:       dsn,
+		pool:       pool,
+		globalCtx:  globalCtx,
+		dsn:        dsn,
+		searchPath: searchPath,
 	}
 
 	cm.Register(func(ctx context.Context) error {
@@ -102,19 +123,58 @@ func (rw *RisingWave) Pool() *pgxpool.Pool {
 	return rw.pool
 }
 
-func (rw *RisingWave) QueryDatabase(ctx context.Context, sql string) (*apigen.QueryResponse, error) {
-	result, err := query(ctx, rw.Pool(), sql, false /*TODO, support background DDL and acuqire conn when true */)
+// timeoutConn returns the connection to run a query of ctx on. If ctx has a deadline, it is a dedicated connection
+// whose statement_timeout is the time left, so that RisingWave stops the query too instead of only the client
+// giving up on it. release restores the timeout and returns the connection to the pool, or closes it if the timeout
+// cannot be restored.
+func (rw *RisingWave) timeoutConn(ctx context.Context) (DB, func(), error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return rw.pool, func() {}, nil
+	}
+	conn, err := rw.pool.Acquire(ctx)
 	if err != nil {
-		if errors.Is(err, ErrQueryFailed) {
-			return &apigen.QueryResponse{
-				Error: utils.Ptr(err.Error()),
-			}, nil
+		return nil, nil, errors.Wrap(ErrUnavailable, err.Error())
+	}
+	release := sync.OnceFunc(func() {
+		if _, err := conn.Exec(context.Background(), "SET statement_timeout TO DEFAULT"); err != nil {
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	})
+	if _, err := conn.Exec(ctx, fmt.Sprintf("SET statement_timeout = %d", statementTimeout(time.Until(deadline)))); err != nil {
+		release()
+		return nil, nil, queryError(err)
+	}
+	return conn, release, nil
+}
+
+// statementTimeout returns the statement_timeout of RisingWave for the time left, in whole seconds and at least one.
+func statementTimeout(left time.Duration) int64 {
+	return max(int64((left+time.Second-1)/time.Second), 1)
+}
+
+func (rw *RisingWave) QueryDatabase(ctx context.Context, sql string, args ...any) (*apigen.QueryResponse, error) {
+	db, release, err := rw.timeoutConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := query(ctx, db, sql, false /* see ExecuteBackgroundDDL */, args...)
+	if err != nil {
+		if errors.Is(err, ErrQueryFailed) || errors.Is(err, ErrUnavailable) {
+			return nil, err
 		}
 		return nil, errors.Wrapf(err, "failed to query database")
 	}
 
-	columns := make([]apigen.Column, len(result.Columns))
-	for i, column := range result.Columns {
+	return result.response(), nil
+}
+
+func (r *Result) response() *apigen.QueryResponse {
+	columns := make([]apigen.Column, len(r.Columns))
+	for i, column := range r.Columns {
 		columns[i] = apigen.Column{
 			Name: column.Name,
 			Type: column.Type,
@@ -122,14 +182,16 @@ func (rw *RisingWave) QueryDatabase(ctx context.Context, sql string) (*apigen.Qu
 	}
 
 	return &apigen.QueryResponse{
-		Columns: columns,
-		Rows:    result.Rows,
-	}, nil
+		Columns:      columns,
+		Rows:         r.Rows,
+		RowsAffected: r.RowsAffected,
+	}
 }
 
 type DB interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 type Result struct {
@@ -138,28 +200,22 @@ type Result struct {
 	Rows         []map[string]any
 }
 
-func query(ctx context.Context, db DB, query string, backgroundDDL bool) (*Result, error) {
+func query(ctx context.Context, db DB, query string, backgroundDDL bool, args ...any) (*Result, error) {
 	if backgroundDDL {
 		_, err := db.Exec(ctx, "SET BACKGROUND_DDL = true")
 		if err != nil {
-			return nil, errors.Wrap(ErrQueryFailed, err.Error())
+			return nil, queryError(err)
 		}
 	}
 
-	rows, err := db.Query(ctx, query)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, errors.Wrap(ErrQueryFailed, err.Error())
+		return nil, queryError(err)
 	}
 	defer rows.Close()
 
 	fieldDescs := rows.FieldDescriptions()
-	columns := make([]Column, len(fieldDescs))
-	for i, d := range fieldDescs {
-		columns[i] = Column{
-			Name: string(d.Name),
-			Type: getDataTypeName(d.DataTypeOID),
-		}
-	}
+	columns := columnsOf(rows)
 
 	var result []map[string]any
 	for rows.Next() {
@@ -170,19 +226,14 @@ func query(ctx context.Context, db DB, query string, backgroundDDL bool) (*Resul
 		}
 
 		if err := rows.Scan(scanArgs...); err != nil {
-			return nil, err
-		}
-
-		row := make(map[string]any, len(fieldDescs))
-		for i, col := range fieldDescs {
-			row[string(col.Name)] = values[i]
+			return nil, queryError(err)
 		}
 
-		result = append(result, row)
+		result = append(result, JSONRow(columns, values))
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(ErrQueryFailed, err.Error())
+		return nil, queryError(err)
 	}
 
 	return &Result{
@@ -192,23 +243,14 @@ func query(ctx context.Context, db DB, query string, backgroundDDL bool) (*Resul
 	}, nil
 }
 
-func getDataTypeName(oid uint32) string {
-	typeMap := map[uint32]string{
-		16:   "boolean",
-		20:   "bigint",
-		21:   "smallint",
-		23:   "integer",
-		25:   "text",
-		700:  "real",
-		701:  "double precision",
-		1043: "varchar",
-		1114: "timestamp",
-		1184: "timestamptz",
-		2950: "uuid",
-	}
-
-	if name, ok := typeMap[oid]; ok {
-		return name
-	}
-	return fmt.Sprintf("unknown_OID(%d)", oid)
+func columnsOf(rows pgx.Rows) []Column {
+	fieldDescs := rows.FieldDescriptions()
+	columns := make([]Column, len(fieldDescs))
+	for i, d := range fieldDescs {
+		columns[i] = Column{
+			Name: string(d.Name),
+			Type: getDataTypeName(d.DataTypeOID),
+		}
+	}
+	return columns
 }
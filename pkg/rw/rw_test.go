@@ -0,0 +1,21 @@
+package rw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	require.Equal(t, int64(30), statementTimeout(30*time.Second))
+	require.Equal(t, int64(2), statementTimeout(1500*time.Millisecond))
+	require.Equal(t, int64(1), statementTimeout(time.Millisecond))
+	require.Equal(t, int64(1), statementTimeout(-time.Second))
+}
+
+func TestResultResponseRowsAffected(t *testing.T) {
+	// counts beyond int32 are reported as they are
+	r := &Result{RowsAffected: 3_000_000_000}
+	require.Equal(t, int64(3_000_000_000), r.response().RowsAffected)
+}
@@ -0,0 +1,136 @@
+package rw
+
+import (
+	"math"
+	"strings"
+)
+
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// arrayItemSchemas are the JSON Schemas of the elements of the array types parseArray accepts, keyed by element type.
+var arrayItemSchemas = map[string]map[string]any{
+	"character varying":      {"type": "string"},
+	"interval":               {"type": "string"},
+	"date":                   {"type": "string", "format": "date"},
+	"time":                   {"type": "string"},
+	"timestamp":              {"type": "string"},
+	"timestamptz":            {"type": "string", "format": "date-time"},
+	"time with time zone":    {"type": "string"},
+	"time without time zone": {"type": "string"},
+	"rw_int256":              {"type": "string"},
+	"integer":                {"type": "integer", "minimum": math.MinInt32, "maximum": math.MaxInt32},
+	"jsonb":                  {},
+	"bigint":                 {"type": "integer"},
+	"double precision":       {"type": "number"},
+	"boolean":                {"type": "boolean"},
+	"smallint":               {"type": "integer", "minimum": math.MinInt16, "maximum": math.MaxInt16},
+	"real":                   {"type": "number"},
+	"numeric":                {"type": "string"},
+	"bytea":                  {"type": "string", "contentEncoding": "base64"},
+}
+
+// JSONSchema returns the JSON Schema of the events of a table. Events are objects whose fields are the ingestible
+// columns of the table, missing fields and unknown fields are ignored. Primary key columns are required, and events of
+// tables with a primary key may select their operation with EventOpField. The enriched columns are left out, the
+// server fills them.
+func JSONSchema(r Relation, enriched map[string]EnrichSource) map[string]any {
+	var (
+		props    = make(map[string]any)
+		required = []string{}
+	)
+	for _, c := range r.Columns {
+		if _, ok := enriched[c.Name]; ok || !Ingestible(c) {
+			continue
+		}
+		props[c.Name] = ColumnSchema(c.Type)
+		if c.IsPrimaryKey {
+			required = append(required, c.Name)
+		}
+	}
+
+	if _, ok := props[EventOpField]; !ok && len(required) > 0 {
+		props[EventOpField] = map[string]any{
+			"type":        "string",
+			"enum":        []string{EventInsert.String(), EventUpsert.String(), EventDelete.String()},
+			"description": "The operation of the event, default is insert. Deletes only need the primary key.",
+		}
+	}
+
+	s := map[string]any{
+		"$schema":    JSONSchemaDialect,
+		"title":      r.QualifiedName(),
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// ColumnSchema returns the JSON Schema of the values of a column type in events. Scalars are decoded as plain JSON
+// values and converted by RisingWave, arrays are decoded by parseArray. Every column accepts null.
+func ColumnSchema(typ string) map[string]any {
+	if strings.HasSuffix(typ, "[]") {
+		return nullable(arraySchema(typ))
+	}
+	return nullable(scalarSchema(typ))
+}
+
+func arraySchema(typ string) map[string]any {
+	itemTyp := strings.TrimSuffix(typ, "[]")
+	if strings.HasSuffix(itemTyp, "[]") {
+		return map[string]any{"type": "array", "items": arraySchema(itemTyp)}
+	}
+	if strings.HasPrefix(itemTyp, "struct") {
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	}
+	if items, ok := arrayItemSchemas[itemTyp]; ok {
+		return map[string]any{"type": "array", "items": items}
+	}
+	// the only value of an unsupported array column is null
+	return map[string]any{"type": "null", "description": "arrays of " + itemTyp + " are not supported"}
+}
+
+func scalarSchema(typ string) map[string]any {
+	if strings.HasPrefix(typ, "struct") {
+		return map[string]any{"type": "object"}
+	}
+	switch typ {
+	case "smallint":
+		return map[string]any{"type": "integer", "minimum": math.MinInt16, "maximum": math.MaxInt16}
+	case "integer", "serial":
+		return map[string]any{"type": "integer", "minimum": math.MinInt32, "maximum": math.MaxInt32}
+	case "bigint":
+		return map[string]any{"type": "integer"}
+	case "real", "double precision":
+		return map[string]any{"type": "number"}
+	case "numeric", "rw_int256":
+		return map[string]any{"type": []string{"number", "string"}}
+	case "boolean":
+		return map[string]any{"type": "boolean"}
+	case "date":
+		return map[string]any{"type": "string", "format": "date"}
+	case "timestamptz", "timestamp with time zone":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "character varying", "varchar", "text", "bytea", "interval",
+		"time", "time without time zone", "time with time zone",
+		"timestamp", "timestamp without time zone":
+		return map[string]any{"type": "string"}
+	}
+	// jsonb and types without a known representation accept any value
+	return map[string]any{}
+}
+
+// nullable adds null to the types of a schema, schemas without a type already accept null.
+func nullable(s map[string]any) map[string]any {
+	switch t := s["type"].(type) {
+	case string:
+		if t != "null" {
+			s["type"] = []string{t, "null"}
+		}
+	case []string:
+		s["type"] = append(t, "null")
+	}
+	return s
+}
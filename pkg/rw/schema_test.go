@@ -0,0 +1,45 @@
+package rw
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayItemSchemasMatchParseArray(t *testing.T) {
+	for typ := range arrayItemSchemas {
+		_, err := parseArray(json.RawMessage("[]"), typ+"[]")
+		require.NoError(t, err, typ)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(Relation{
+		Schema: "public",
+		Name:   "clickstream",
+		Columns: []Column{
+			{Name: "id", Type: "bigint", IsPrimaryKey: true},
+			{Name: "tags", Type: "character varying[]"},
+			{Name: "amount", Type: "numeric"},
+			{Name: "payload", Type: "jsonb"},
+			{Name: "_row_id", Type: "serial", IsHidden: true},
+		},
+	}, nil)
+
+	raw, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "public.clickstream",
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": ["integer", "null"]},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"amount": {"type": ["number", "string", "null"]},
+			"payload": {},
+			"_op": {"type": "string", "enum": ["insert", "upsert", "delete"], "description": "The operation of the event, default is insert. Deletes only need the primary key."}
+		}
+	}`, string(raw))
+}
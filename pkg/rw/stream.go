@@ -0,0 +1,116 @@
+package rw
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// RowStream iterates the rows of a query as they are received from RisingWave, without buffering the result.
+type RowStream struct {
+	ctx     context.Context
+	rows    pgx.Rows
+	release func()
+	Columns []Column
+
+	prefetched bool
+}
+
+// StreamQuery runs the query and returns a stream of its rows. The first row is fetched before returning so that
+// errors of the query are reported before the caller starts writing the response.
+func (rw *RisingWave) StreamQuery(ctx context.Context, sql string, args ...any) (*RowStream, error) {
+	db, release, err := rw.timeoutConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		release()
+		return nil, queryError(err)
+	}
+
+	s := &RowStream{
+		ctx:     ctx,
+		rows:    rows,
+		release: release,
+		Columns: columnsOf(rows),
+	}
+
+	s.prefetched = rows.Next()
+	if !s.prefetched {
+		if err := rows.Err(); err != nil {
+			s.Close()
+			return nil, queryError(err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *RowStream) Next() bool {
+	if s.prefetched {
+		s.prefetched = false
+		return true
+	}
+	return s.rows.Next()
+}
+
+func (s *RowStream) Values() ([]any, error) {
+	return s.rows.Values()
+}
+
+// Err returns the error that ended the stream. If the context was cancelled, its cause is returned instead, e.g.
+// ErrQueryTimeout.
+func (s *RowStream) Err() error {
+	if err := s.rows.Err(); err != nil {
+		if cause := context.Cause(s.ctx); cause != nil {
+			return cause
+		}
+		return queryError(err)
+	}
+	return nil
+}
+
+func (s *RowStream) Close() {
+	s.rows.Close()
+	s.release()
+}
+
+// CopyRows encodes every row of the stream. flush is called every flushEvery rows so that rows reach the client
+// while the query is still running, an error from flush aborts the copy.
+func CopyRows(s *RowStream, enc RowEncoder, flushEvery int, flush func() error) error {
+	err := copyRows(s, enc, flushEvery, flush)
+	if endErr := enc.End(err); err == nil {
+		err = endErr
+	}
+	if err == nil {
+		err = flush()
+	}
+	return err
+}
+
+func copyRows(s *RowStream, enc RowEncoder, flushEvery int, flush func() error) error {
+	if err := enc.Begin(s.Columns); err != nil {
+		return err
+	}
+
+	n := 0
+	for s.Next() {
+		values, err := s.Values()
+		if err != nil {
+			return errors.Wrap(err, "failed to read row")
+		}
+		if err := enc.Encode(values); err != nil {
+			return errors.Wrap(err, "failed to encode row")
+		}
+		n++
+		if n%flushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.Err()
+}
@@ -0,0 +1,364 @@
+package rw
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSubscriptionRetention = "1D"
+	DefaultMaxSubscriptions      = 100
+
+	subscriptionFetchSize    = 100
+	subscriptionFetchTimeout = 1 * time.Second
+	subscriptionCursor       = "events_api_cursor"
+	subscriptionPrefix       = "events_api_sub_"
+)
+
+var ErrTooManySubscriptions = errors.New("too many subscriptions")
+
+const (
+	OpInsert       = "insert"
+	OpDelete       = "delete"
+	OpUpdateInsert = "update_insert"
+	OpUpdateDelete = "update_delete"
+)
+
+// ChangeEvent is a row change of a subscribed relation.
+type ChangeEvent struct {
+	Op string `json:"op"`
+	// Timestamp is the rw_timestamp of the change, it can be used to resume the subscription.
+	Timestamp int64          `json:"rw_timestamp"`
+	Row       map[string]any `json:"row"`
+}
+
+// listSubscriptionsSQL lists the subscriptions created by the API, including those of earlier runs.
+const listSubscriptionsSQL = `SELECT sc.name, s.name FROM rw_catalog.rw_subscriptions s
+JOIN rw_catalog.rw_schemas sc ON s.schema_id = sc.id
+WHERE s.name LIKE 'events\_api\_sub\_%'`
+
+// subscriptionState tracks the cursors open on a RisingWave subscription created by the API.
+type subscriptionState struct {
+	// ident is the quoted name of the subscription
+	ident string
+	refs  int
+	// idleSince is when the last cursor was closed
+	idleSince time.Time
+}
+
+// Subscriber opens subscription cursors on dedicated connections from the pool. The RisingWave subscriptions they read
+// are created on first use and dropped once no cursor used them for the retention, since clients cannot resume from
+// them any more by then.
+type Subscriber struct {
+	rw         *RisingWave
+	searchPath SearchPath
+	watcher    *Watcher
+	policy     *statement.Policy
+	retention  string
+	idle       time.Duration
+	slots      chan struct{}
+	log        *zap.Logger
+
+	// mu guards subs and serializes the creation and dropping of subscriptions
+	mu   sync.Mutex
+	subs map[string]*subscriptionState
+}
+
+func NewSubscriber(cfg *config.Config, rw *RisingWave, searchPath SearchPath, watcher *Watcher, policy *statement.Policy, gctx *gctx.GlobalContext, log *zap.Logger) (*Subscriber, error) {
+	retention := cfg.Subscription.Retention
+	if retention == "" {
+		retention = DefaultSubscriptionRetention
+	}
+	idle, err := parseRetention(retention)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid subscription retention")
+	}
+	maxSubs := cfg.Subscription.MaxSubscriptions
+	if maxSubs <= 0 {
+		maxSubs = DefaultMaxSubscriptions
+	}
+	s := &Subscriber{
+		rw:         rw,
+		searchPath: searchPath,
+		watcher:    watcher,
+		policy:     policy,
+		retention:  retention,
+		idle:       idle,
+		slots:      make(chan struct{}, maxSubs),
+		log:        log.Named("subscriber"),
+		subs:       make(map[string]*subscriptionState),
+	}
+	s.adopt(gctx.Context())
+	go s.runCleanup(gctx.Context())
+	return s, nil
+}
+
+// parseRetention parses retentions such as "1D", "12h" or "30 minutes" the way RisingWave does for the common units.
+func parseRetention(s string) (time.Duration, error) {
+	num := strings.TrimRightFunc(strings.TrimSpace(s), func(r rune) bool { return r < '0' || r > '9' })
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return 0, errors.Errorf("invalid retention %q", s)
+	}
+	unit := strings.ToLower(strings.TrimSpace(strings.TrimSpace(s)[len(num):]))
+	switch unit {
+	case "s", "sec", "second", "seconds":
+		return time.Duration(n) * time.Second, nil
+	case "m", "min", "minute", "minutes":
+		return time.Duration(n) * time.Minute, nil
+	case "h", "hour", "hours":
+		return time.Duration(n) * time.Hour, nil
+	case "d", "day", "days":
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return 0, errors.Errorf("invalid retention %q, use seconds, minutes, hours or days", s)
+}
+
+// adopt tracks the subscriptions left by earlier runs as idle, so that they are dropped unless they are used again.
+func (s *Subscriber) adopt(ctx context.Context) {
+	rows, err := s.rw.pool.Query(ctx, listSubscriptionsSQL)
+	if err != nil {
+		s.log.Warn("failed to list subscriptions", zap.Error(err))
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			s.log.Warn("failed to list subscriptions", zap.Error(err))
+			return
+		}
+		relation, _ := strings.CutPrefix(name, subscriptionPrefix)
+		s.subs[FormatName(schema, relation)] = &subscriptionState{
+			ident:     pgx.Identifier{schema, name}.Sanitize(),
+			idleSince: now,
+		}
+	}
+}
+
+func (s *Subscriber) runCleanup(ctx context.Context) {
+	ticker := time.NewTicker(min(s.idle/4+time.Second, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.dropIdle(ctx, now)
+		}
+	}
+}
+
+// dropIdle drops the subscriptions no cursor used for the retention.
+func (s *Subscriber) dropIdle(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, st := range s.subs {
+		if st.refs > 0 || now.Sub(st.idleSince) < s.idle {
+			continue
+		}
+		if _, err := s.rw.pool.Exec(ctx, "DROP SUBSCRIPTION IF EXISTS "+st.ident); err != nil {
+			s.log.Warn("failed to drop idle subscription", zap.String("relation", key), zap.Error(err))
+			continue
+		}
+		s.log.Info("dropped idle subscription", zap.String("relation", key))
+		delete(s.subs, key)
+	}
+}
+
+// ref creates the subscription of a relation if no cursor uses it and counts the cursor.
+func (s *Subscriber) ref(ctx context.Context, r Relation) (string, error) {
+	key := r.QualifiedName()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.subs[key]
+	if !ok || st.refs == 0 {
+		ident := pgx.Identifier{r.Schema, subscriptionPrefix + r.Name}.Sanitize()
+		if _, err := s.rw.pool.Exec(ctx, fmt.Sprintf(
+			"CREATE SUBSCRIPTION IF NOT EXISTS %s FROM %s WITH (retention = '%s')",
+			ident, pgx.Identifier{r.Schema, r.Name}.Sanitize(), s.retention,
+		)); err != nil {
+			return "", queryError(err)
+		}
+		if !ok {
+			st = &subscriptionState{ident: ident}
+			s.subs[key] = st
+		}
+	}
+	st.refs++
+	return st.ident, nil
+}
+
+func (s *Subscriber) unref(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.subs[key]; ok {
+		st.refs--
+		if st.refs == 0 {
+			st.idleSince = time.Now()
+		}
+	}
+}
+
+// Subscription is a subscription cursor held on a dedicated connection. It is not safe for concurrent use.
+type Subscription struct {
+	conn    *pgxpool.Conn
+	release func()
+	log     *zap.Logger
+}
+
+// Open declares a cursor on the subscription of a table or materialized view, creating the subscription if needed.
+// Creating it is checked against the SQL policy of the API key, so keys that cannot run CREATE SUBSCRIPTION cannot
+// subscribe. If since is not nil, changes are replayed from that rw_timestamp, changes with the same timestamp are
+// delivered again. Otherwise only changes after now are delivered.
+func (s *Subscriber) Open(ctx context.Context, apiKeyID, relation string, since *int64) (*Subscription, error) {
+	r, ok := s.watcher.Subscribable(relation)
+	if !ok {
+		return nil, errors.Wrapf(ErrRelationNotFound, "no relation %s to subscribe to", s.searchPath.QualifiedName(relation))
+	}
+	if err := s.policy.Check(apiKeyID, fmt.Sprintf(
+		"CREATE SUBSCRIPTION %s FROM %s", pgx.Identifier{r.Schema, subscriptionPrefix + r.Name}.Sanitize(), pgx.Identifier{r.Schema, r.Name}.Sanitize(),
+	)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return nil, ErrTooManySubscriptions
+	}
+	key := r.QualifiedName()
+	ident, err := s.ref(ctx, r)
+	if err != nil {
+		<-s.slots
+		return nil, err
+	}
+	release := func() {
+		s.unref(key)
+		<-s.slots
+	}
+
+	conn, err := s.rw.pool.Acquire(ctx)
+	if err != nil {
+		release()
+		return nil, errors.Wrap(err, "failed to acquire connection")
+	}
+
+	declare := fmt.Sprintf("DECLARE %s SUBSCRIPTION CURSOR FOR %s", subscriptionCursor, ident)
+	if since != nil {
+		declare += fmt.Sprintf(" SINCE %d", *since)
+	}
+	if _, err := conn.Exec(ctx, declare); err != nil {
+		conn.Release()
+		release()
+		return nil, queryError(err)
+	}
+
+	return &Subscription{
+		conn:    conn,
+		release: release,
+		log:     s.log.With(zap.String("relation", key)),
+	}, nil
+}
+
+// Fetch waits up to one second for changes. An empty result means no change happened in the meantime.
+func (s *Subscription) Fetch(ctx context.Context) ([]ChangeEvent, error) {
+	rows, err := s.conn.Query(ctx, fmt.Sprintf(
+		"FETCH %d FROM %s WITH (timeout = '%ds')",
+		subscriptionFetchSize, subscriptionCursor, int(subscriptionFetchTimeout.Seconds()),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch from subscription cursor")
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	cols := columnsOf(rows)
+
+	var ret []ChangeEvent
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read change")
+		}
+		e := ChangeEvent{Row: make(map[string]any, len(values))}
+		for i, f := range fields {
+			switch f.Name {
+			case "op":
+				e.Op = changeOp(values[i])
+			case "rw_timestamp":
+				if ts, ok := values[i].(int64); ok {
+					e.Timestamp = ts
+				}
+			default:
+				e.Row[f.Name] = JSONValue(cols[i].Type, values[i])
+			}
+		}
+		ret = append(ret, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch from subscription cursor")
+	}
+	return ret, nil
+}
+
+// Close closes the cursor and returns the connection to the pool. The connection is closed instead if the cursor
+// cannot be closed, so that no session state leaks to other users of the pool.
+func (s *Subscription) Close() {
+	defer s.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.conn.Exec(ctx, "CLOSE "+subscriptionCursor); err != nil {
+		s.log.Warn("failed to close subscription cursor, closing connection", zap.Error(err))
+		_ = s.conn.Conn().Close(ctx)
+	}
+	s.conn.Release()
+}
+
+func changeOp(v any) string {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "Insert":
+			return OpInsert
+		case "Delete":
+			return OpDelete
+		case "UpdateInsert":
+			return OpUpdateInsert
+		case "UpdateDelete":
+			return OpUpdateDelete
+		}
+		return strings.ToLower(v)
+	case int16:
+		switch v {
+		case 1:
+			return OpInsert
+		case 2:
+			return OpDelete
+		case 3:
+			return OpUpdateInsert
+		case 4:
+			return OpUpdateDelete
+		}
+	}
+	return fmt.Sprint(v)
+}
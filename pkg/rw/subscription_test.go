@@ -0,0 +1,63 @@
+package rw
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetention(t *testing.T) {
+	for s, d := range map[string]time.Duration{
+		"1D":         24 * time.Hour,
+		"12h":        12 * time.Hour,
+		"30 minutes": 30 * time.Minute,
+		"90s":        90 * time.Second,
+	} {
+		got, err := parseRetention(s)
+		require.NoError(t, err, s)
+		require.Equal(t, d, got, s)
+	}
+	for _, s := range []string{"", "D", "0h", "1w", "-1d"} {
+		_, err := parseRetention(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestChangeOp(t *testing.T) {
+	require.Equal(t, OpInsert, changeOp("Insert"))
+	require.Equal(t, OpUpdateDelete, changeOp(int16(4)))
+}
+
+func TestOpenResolvesMaterializedViews(t *testing.T) {
+	searchPath := SearchPath{"public"}
+	w := &Watcher{
+		searchPath: searchPath,
+		relations: map[string]Relation{
+			"public.clicks": {Schema: "public", Name: "clicks", Type: RelationTypeTable},
+		},
+		views: map[string]Relation{
+			"public.clicks_per_minute": {Schema: "public", Name: "clicks_per_minute", Type: RelationTypeMaterializedView},
+		},
+	}
+	// a read-only policy rejects the subscription once the relation is resolved, so no connection is needed
+	policy, err := statement.NewPolicy(&config.Config{SQL: config.SQL{SQLPolicy: config.SQLPolicy{Mode: statement.ModeReadOnly}}})
+	require.NoError(t, err)
+	s := &Subscriber{searchPath: searchPath, watcher: w, policy: policy}
+
+	for _, name := range []string{"clicks", "clicks_per_minute", "public.clicks_per_minute"} {
+		_, err := s.Open(context.Background(), "", name, nil)
+		var violation *statement.Violation
+		require.ErrorAs(t, err, &violation, name)
+	}
+
+	_, err = s.Open(context.Background(), "", "missing", nil)
+	require.ErrorIs(t, err, ErrRelationNotFound)
+
+	// materialized views can be subscribed to, but are not ingested into
+	_, ok := w.Relation("clicks_per_minute")
+	require.False(t, ok)
+}
@@ -0,0 +1,177 @@
+package rw
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OIDs of RisingWave types that PostgreSQL does not have.
+const (
+	Int256OID      = 1301
+	Int256ArrayOID = 1302
+)
+
+// sqlTypeNames maps the pgx names of the types to the names used by SQL, which are the names reported in query
+// results. Types that are not listed are reported with their pgx name.
+var sqlTypeNames = map[string]string{
+	"bool":   "boolean",
+	"int2":   "smallint",
+	"int4":   "integer",
+	"int8":   "bigint",
+	"float4": "real",
+	"float8": "double precision",
+	"bpchar": "char",
+	"record": "struct",
+}
+
+// typeMap is used to name the types of result columns, connections of the pool get the same registrations.
+var typeMap = newTypeMap()
+
+func newTypeMap() *pgtype.Map {
+	m := pgtype.NewMap()
+	registerTypes(m)
+	return m
+}
+
+// registerTypes adds the RisingWave types to the type map. They are decoded as text, as are structs, which pgx only
+// supports in the binary format.
+func registerTypes(m *pgtype.Map) {
+	int256 := &pgtype.Type{Name: "rw_int256", OID: Int256OID, Codec: pgtype.TextCodec{}}
+	m.RegisterType(int256)
+	m.RegisterType(&pgtype.Type{Name: "_rw_int256", OID: Int256ArrayOID, Codec: &pgtype.ArrayCodec{ElementType: int256}})
+	m.RegisterType(&pgtype.Type{Name: "record", OID: pgtype.RecordOID, Codec: pgtype.TextCodec{}})
+}
+
+func getDataTypeName(oid uint32) string {
+	t, ok := typeMap.TypeForOID(oid)
+	if !ok {
+		return fmt.Sprintf("unknown_OID(%d)", oid)
+	}
+	if c, ok := t.Codec.(*pgtype.ArrayCodec); ok {
+		return getDataTypeName(c.ElementType.OID) + "[]"
+	}
+	if name, ok := sqlTypeNames[t.Name]; ok {
+		return name
+	}
+	return t.Name
+}
+
+// JSONRow converts the values of a row to their JSON representation, keyed by column name.
+func JSONRow(cols []Column, values []any) map[string]any {
+	row := make(map[string]any, len(values))
+	for i, v := range values {
+		row[cols[i].Name] = JSONValue(cols[i].Type, v)
+	}
+	return row
+}
+
+// JSONValue converts a value decoded by pgx to the representation clients can rely on, whatever the output format:
+// numerics are strings to keep their precision, bytea is base64, intervals are ISO-8601 durations, timestamps are
+// RFC 3339 in UTC, dates are YYYY-MM-DD, times are HH:MM:SS[.ffffff] and non-finite floats are "NaN", "Infinity"
+// and "-Infinity". typ is the type name of the column.
+func JSONValue(typ string, v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int16, int32, int64, map[string]any:
+		return v
+	case float32:
+		return jsonFloat(float64(v), v)
+	case float64:
+		return jsonFloat(v, v)
+	case time.Time:
+		if typ == "date" {
+			return v.Format(time.DateOnly)
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case [16]byte:
+		return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+	case pgtype.Interval:
+		if !v.Valid {
+			return nil
+		}
+		return FormatInterval(v)
+	case pgtype.Time:
+		if !v.Valid {
+			return nil
+		}
+		if v.Microseconds == int64(24*time.Hour/time.Microsecond) {
+			return "24:00:00"
+		}
+		return time.UnixMicro(v.Microseconds).UTC().Format("15:04:05.999999")
+	case pgtype.InfinityModifier:
+		return v.String()
+	case []any:
+		if typ == "json" || typ == "jsonb" {
+			return v
+		}
+		elemTyp := strings.TrimSuffix(typ, "[]")
+		ret := make([]any, len(v))
+		for i, e := range v {
+			ret[i] = JSONValue(elemTyp, e)
+		}
+		return ret
+	case driver.Valuer:
+		// pgtype.Numeric and the like, whose text form is the one PostgreSQL uses
+		dv, err := v.Value()
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return JSONValue(typ, dv)
+	}
+	return v
+}
+
+func jsonFloat(f float64, v any) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return v
+}
+
+// FormatInterval formats the interval as an ISO-8601 duration the way PostgreSQL does with intervalstyle
+// iso_8601, each component carrying its own sign, e.g. P1Y2M3DT4H5M6.5S.
+func FormatInterval(iv pgtype.Interval) string {
+	if iv.Months == 0 && iv.Days == 0 && iv.Microseconds == 0 {
+		return "PT0S"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("P")
+	if y := iv.Months / 12; y != 0 {
+		fmt.Fprintf(&sb, "%dY", y)
+	}
+	if m := iv.Months % 12; m != 0 {
+		fmt.Fprintf(&sb, "%dM", m)
+	}
+	if iv.Days != 0 {
+		fmt.Fprintf(&sb, "%dD", iv.Days)
+	}
+
+	if us := iv.Microseconds; us != 0 {
+		sb.WriteString("T")
+		if h := us / int64(time.Hour/time.Microsecond); h != 0 {
+			fmt.Fprintf(&sb, "%dH", h)
+		}
+		if m := us / int64(time.Minute/time.Microsecond) % 60; m != 0 {
+			fmt.Fprintf(&sb, "%dM", m)
+		}
+		if s := us % int64(time.Minute/time.Microsecond); s != 0 {
+			sb.WriteString(strconv.FormatFloat(float64(s)/1e6, 'f', -1, 64))
+			sb.WriteString("S")
+		}
+	}
+	return sb.String()
+}
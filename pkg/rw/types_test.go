@@ -0,0 +1,50 @@
+package rw
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDataTypeName(t *testing.T) {
+	require.Equal(t, "bigint", getDataTypeName(pgtype.Int8OID))
+	require.Equal(t, "numeric", getDataTypeName(pgtype.NumericOID))
+	require.Equal(t, "timestamptz", getDataTypeName(pgtype.TimestamptzOID))
+	require.Equal(t, "integer[]", getDataTypeName(pgtype.Int4ArrayOID))
+	require.Equal(t, "rw_int256[]", getDataTypeName(Int256ArrayOID))
+	require.Equal(t, "struct", getDataTypeName(pgtype.RecordOID))
+	require.Equal(t, "unknown_OID(424242)", getDataTypeName(424242))
+}
+
+func TestJSONValue(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("", 3600))
+
+	testCases := []struct {
+		typ      string
+		value    any
+		expected any
+	}{
+		{"numeric", pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, "123.45"},
+		{"numeric", pgtype.Numeric{NaN: true, Valid: true}, "NaN"},
+		{"bytea", []byte("hi"), "aGk="},
+		{"timestamptz", ts, "2024-01-15T09:30:00Z"},
+		{"date", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "2024-01-15"},
+		{"date", pgtype.Infinity, "infinity"},
+		{"time", pgtype.Time{Microseconds: 3723500000, Valid: true}, "01:02:03.5"},
+		{"interval", pgtype.Interval{Months: 14, Days: 3, Microseconds: 4*3600e6 + 5*60e6 + 6.5e6, Valid: true}, "P1Y2M3DT4H5M6.5S"},
+		{"interval", pgtype.Interval{Microseconds: -90e6, Valid: true}, "PT-1M-30S"},
+		{"interval", pgtype.Interval{Valid: true}, "PT0S"},
+		{"double precision", math.Inf(-1), "-Infinity"},
+		{"uuid", [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}, "12345678-9abc-def0-1234-56789abcdef0"},
+		{"date[]", []any{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), nil}, []any{"2024-01-15", nil}},
+		{"jsonb", map[string]any{"a": 1.5}, map[string]any{"a": 1.5}},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, JSONValue(tc.typ, tc.value), tc.typ)
+	}
+}
@@ -2,50 +2,257 @@ package rw
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/app/zgen/apigen"
+	"github.com/risingwavelabs/events-api/pkg/config"
 	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/statement"
 	"go.uber.org/zap"
 )
 
 type RelationType string
 
-const watcherPollInterval = 1 * time.Second
+const (
+	RelationTypeTable            RelationType = "table"
+	RelationTypeMaterializedView RelationType = "materialized view"
+)
 
-type Watcher struct {
-	rw   *RisingWave
-	gctx *gctx.GlobalContext
-	log  *zap.Logger
+const defaultCatalogPollInterval = 1 * time.Second
 
-	mu            sync.RWMutex
-	lastKeyToDefs map[string]string
+type Watcher struct {
+	rw         *RisingWave
+	searchPath SearchPath
+	gctx       *gctx.GlobalContext
+	interval   time.Duration
+	log        *zap.Logger
+
+	// updateMu serializes cache updates, fingerprint is the catalog fingerprint of the last update
+	updateMu    sync.Mutex
+	fingerprint string
+
+	// trackDeps is set once a component needs the dependents of relations, see TrackDependencies
+	trackDeps atomic.Bool
+
+	mu        sync.RWMutex
+	relations map[string]Relation
+	listeners []watcherListener
+	// dependents are the relations that directly depend on a relation, e.g. the materialized views reading a table
+	dependents map[string][]string
+	// views are the materialized views, they can be subscribed to but are never ingested into
+	views map[string]Relation
+}
 
+type watcherListener struct {
 	onRelationUpdate func(relation Relation) error
 	onRelationDelete func(name string) error
 }
 
-func NewWatcher(
-	rw *RisingWave,
-	gctx *gctx.GlobalContext,
-	log *zap.Logger,
-	onRelationUpdate func(relation Relation) error,
-	onRelationDelete func(name string) error,
-) *Watcher {
-	return &Watcher{
-		rw:               rw,
-		gctx:             gctx,
-		log:              log.Named("watcher"),
+// NewWatcher loads the relations of RisingWave and keeps them up to date in the background.
+func NewWatcher(cfg *config.Config, rw *RisingWave, searchPath SearchPath, gctx *gctx.GlobalContext, log *zap.Logger) (*Watcher, error) {
+	w := &Watcher{
+		rw:         rw,
+		searchPath: searchPath,
+		gctx:       gctx,
+		interval:   defaultCatalogPollInterval,
+		log:        log.Named("watcher"),
+		relations:  make(map[string]Relation),
+		views:      make(map[string]Relation),
+	}
+	if cfg.Catalog.PollInterval != "" {
+		d, err := time.ParseDuration(cfg.Catalog.PollInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid catalog poll interval")
+		}
+		if d <= 0 {
+			return nil, errors.Errorf("invalid catalog poll interval %s", cfg.Catalog.PollInterval)
+		}
+		w.interval = d
+	}
+	if err := w.UpdateCache(gctx.Context()); err != nil { // initial cache update
+		return nil, errors.Wrap(err, "failed to perform initial cache update")
+	}
+	go w.Start()
+	return w, nil
+}
+
+// AddListener registers callbacks for relation changes. onRelationUpdate is called right away for every known
+// relation.
+func (w *Watcher) AddListener(onRelationUpdate func(relation Relation) error, onRelationDelete func(name string) error) {
+	// no update can run in between, so the listener misses no change
+	w.updateMu.Lock()
+	defer w.updateMu.Unlock()
+
+	w.mu.Lock()
+	w.listeners = append(w.listeners, watcherListener{
 		onRelationUpdate: onRelationUpdate,
 		onRelationDelete: onRelationDelete,
-		lastKeyToDefs:    make(map[string]string),
+	})
+	relations := maps.Clone(w.relations)
+	w.mu.Unlock()
+
+	// listeners are called without the lock, they may look relations up
+	for k, v := range relations {
+		if err := onRelationUpdate(v); err != nil {
+			w.log.Error("failed to handle relation update", zap.String("relation", k), zap.Error(err))
+		}
+	}
+}
+
+// Relation returns the cached relation with the given name, unqualified names are looked up in the schemas of the
+// search path in order.
+func (w *Watcher) Relation(name string) (Relation, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, key := range w.searchPath.CandidateNames(name) {
+		if r, ok := w.relations[key]; ok {
+			return r, true
+		}
+	}
+	return Relation{}, false
+}
+
+// Subscribable returns the table or materialized view with the given name, unqualified names are looked up in the
+// schemas of the search path in order. Materialized views have no columns.
+func (w *Watcher) Subscribable(name string) (Relation, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, key := range w.searchPath.CandidateNames(name) {
+		if r, ok := w.relations[key]; ok {
+			return r, true
+		}
+		if r, ok := w.views[key]; ok {
+			return r, true
+		}
+	}
+	return Relation{}, false
+}
+
+// Relations returns the cached relations ordered by schema and name.
+func (w *Watcher) Relations() []Relation {
+	w.mu.RLock()
+	ret := make([]Relation, 0, len(w.relations))
+	for _, r := range w.relations {
+		ret = append(ret, r)
+	}
+	w.mu.RUnlock()
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Schema != ret[j].Schema {
+			return ret[i].Schema < ret[j].Schema
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// TrackDependencies loads the dependencies between relations and keeps them up to date, so that Dependents can be
+// used. They are not tracked by default, since they cost a query on every poll.
+func (w *Watcher) TrackDependencies(ctx context.Context) error {
+	w.trackDeps.Store(true)
+	return w.updateDependencies(ctx)
+}
+
+// Dependents returns the canonical names of the relations that depend on a relation directly or through other
+// relations, such as materialized views built on materialized views of a table. Unqualified names are looked up in
+// every schema of the search path.
+func (w *Watcher) Dependents(name string) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return dependentsOf(w.dependents, w.searchPath.CandidateNames(name))
+}
+
+func dependentsOf(dependents map[string][]string, names []string) []string {
+	var (
+		ret  []string
+		seen = make(map[string]bool)
+	)
+	queue := slices.Clone(names)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[name] {
+			if !seen[d] {
+				seen[d] = true
+				ret = append(ret, d)
+				queue = append(queue, d)
+			}
+		}
+	}
+	return ret
+}
+
+func (w *Watcher) updateDependencies(ctx context.Context) error {
+	rows, err := w.rw.pool.Query(ctx, getDependenciesSQL)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch dependencies from RisingWave")
+	}
+	defer rows.Close()
+
+	dependents := make(map[string][]string)
+	for rows.Next() {
+		var schema, name, depSchema, depName string
+		if err := rows.Scan(&schema, &name, &depSchema, &depName); err != nil {
+			return errors.Wrap(err, "failed to scan dependency row")
+		}
+		key := FormatName(schema, name)
+		dependents[key] = append(dependents[key], FormatName(depSchema, depName))
+	}
+	if rows.Err() != nil {
+		return errors.Wrap(rows.Err(), "error occurred during rows iteration")
+	}
+
+	w.mu.Lock()
+	w.dependents = dependents
+	w.mu.Unlock()
+	return nil
+}
+
+// AnnotateColumns sets IsPrimaryKey and IsHidden of the result columns of a query. Only a single SELECT whose rows
+// are rows of one table is annotated, and only its columns that are plain references to table columns.
+func (w *Watcher) AnnotateColumns(sql string, cols []apigen.Column) {
+	stmts, err := statement.Split(sql)
+	if err != nil || len(stmts) != 1 {
+		return
+	}
+	src, ok := stmts[0].Source()
+	if !ok {
+		return
+	}
+	rel, ok := w.Relation(src.Table)
+	if !ok {
+		return
+	}
+
+	relCols := make(map[string]Column, len(rel.Columns))
+	for _, c := range rel.Columns {
+		relCols[c.Name] = c
+	}
+	for i := range cols {
+		name, ok := src.Columns[cols[i].Name]
+		if !ok {
+			if !src.Star {
+				continue
+			}
+			name = cols[i].Name
+		}
+		if c, ok := relCols[name]; ok {
+			cols[i].IsPrimaryKey = c.IsPrimaryKey
+			cols[i].IsHidden = c.IsHidden
+		}
 	}
 }
 
 func (w *Watcher) Start() {
-	ticker := time.NewTicker(watcherPollInterval)
+	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
 
 	w.log.Info("starting RisingWave watcher")
@@ -62,6 +269,11 @@ func (w *Watcher) Start() {
 				if err := w.UpdateCache(ctx); err != nil {
 					w.log.Error("failed to update cache", zap.Error(err))
 				}
+				if w.trackDeps.Load() {
+					if err := w.updateDependencies(ctx); err != nil {
+						w.log.Error("failed to update dependencies", zap.Error(err))
+					}
+				}
 			}()
 		}
 	}
@@ -110,7 +322,29 @@ const getRelationsSQL = `SELECT
 	rw_relations.definition
 FROM rw_relations 
 JOIN rw_schemas ON rw_schemas.id = rw_relations.schema_id
-WHERE relation_type = 'table'
+WHERE relation_type IN ('table', 'materialized view')
+`
+
+// getFingerprintSQL summarizes the tables and materialized views of the catalog, it changes whenever one of them is
+// created, altered or dropped.
+const getFingerprintSQL = `SELECT
+	COUNT(*),
+	COALESCE(md5(string_agg(id::VARCHAR || ':' || definition, ',' ORDER BY id)), '')
+FROM rw_relations
+WHERE relation_type IN ('table', 'materialized view')
+`
+
+// getDependenciesSQL lists the relations and the relations that depend on them.
+const getDependenciesSQL = `SELECT
+	ref_schema.name,
+	ref.name,
+	obj_schema.name,
+	obj.name
+FROM rw_catalog.rw_depend
+JOIN rw_catalog.rw_relations ref        ON ref.id = rw_depend.refobjid
+JOIN rw_catalog.rw_schemas   ref_schema ON ref_schema.id = ref.schema_id
+JOIN rw_catalog.rw_relations obj        ON obj.id = rw_depend.objid
+JOIN rw_catalog.rw_schemas   obj_schema ON obj_schema.id = obj.schema_id
 `
 
 const getColumnsSQL = `SELECT 
@@ -125,10 +359,28 @@ const getColumnsSQL = `SELECT
 FROM rw_columns
 JOIN rw_relations ON rw_relations.id = rw_columns.relation_id
 JOIN rw_schemas   ON rw_schemas.id = rw_relations.schema_id
-WHERE rw_relations.relation_type = 'table'
+WHERE rw_relations.relation_type = 'table' AND rw_relations.id = ANY($1::INT[])
 `
 
+// UpdateCache refetches the relations that changed since the last update. The catalog fingerprint is checked first,
+// so that an unchanged catalog costs a single aggregate query, and only the columns of changed relations are
+// fetched. Besides the periodic updates, it is called right after DDL so that new tables are usable immediately.
 func (w *Watcher) UpdateCache(ctx context.Context) error {
+	w.updateMu.Lock()
+	defer w.updateMu.Unlock()
+
+	var (
+		count int64
+		hash  string
+	)
+	if err := w.rw.pool.QueryRow(ctx, getFingerprintSQL).Scan(&count, &hash); err != nil {
+		return errors.Wrap(err, "failed to fetch catalog fingerprint from RisingWave")
+	}
+	fingerprint := fmt.Sprintf("%d:%s", count, hash)
+	if fingerprint == w.fingerprint {
+		return nil
+	}
+
 	rows, err := w.rw.pool.Query(ctx, getRelationsSQL)
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch relations from RisingWave")
@@ -136,6 +388,7 @@ func (w *Watcher) UpdateCache(ctx context.Context) error {
 	defer rows.Close()
 
 	updatedRelations := make(map[string]Relation)
+	views := make(map[string]Relation)
 
 	newlyFetched := make(map[string]struct{})
 	for rows.Next() {
@@ -159,12 +412,19 @@ func (w *Watcher) UpdateCache(ctx context.Context) error {
 			Definition: definition,
 		}
 
-		key := schema + "." + relationName
+		key := FormatName(schema, relationName)
+
+		if relationType == RelationTypeMaterializedView {
+			views[key] = relation
+			continue
+		}
 
 		newlyFetched[key] = struct{}{}
 
-		_, exist := w.lastKeyToDefs[key]
-		if exist && w.lastKeyToDefs[key] == definition {
+		w.mu.RLock()
+		last, exist := w.relations[key]
+		w.mu.RUnlock()
+		if exist && last.Definition == definition {
 			continue
 		}
 
@@ -179,8 +439,57 @@ func (w *Watcher) UpdateCache(ctx context.Context) error {
 	if rows.Err() != nil {
 		return errors.Wrap(rows.Err(), "error occurred during rows iteration")
 	}
+	rows.Close()
+
+	if len(updatedRelations) > 0 {
+		if err := w.fetchColumns(ctx, updatedRelations); err != nil {
+			return err
+		}
+	}
 
-	rows, err = w.rw.pool.Query(ctx, getColumnsSQL)
+	var deleted []string
+	w.mu.Lock()
+	w.views = views
+	maps.Copy(w.relations, updatedRelations)
+	for k := range w.relations {
+		if _, exist := newlyFetched[k]; !exist {
+			w.log.Info("relation deleted", zap.String("relation", k))
+			delete(w.relations, k)
+			deleted = append(deleted, k)
+		}
+	}
+	listeners := slices.Clone(w.listeners)
+	w.mu.Unlock()
+
+	// listeners are called without the lock, they may look relations up. Updates are serialized by updateMu, so they
+	// see the changes in order.
+	for k, v := range updatedRelations {
+		for _, l := range listeners {
+			if err := l.onRelationUpdate(v); err != nil {
+				w.log.Error("failed to handle relation update", zap.String("relation", k), zap.Error(err))
+			}
+		}
+	}
+	for _, k := range deleted {
+		for _, l := range listeners {
+			if err := l.onRelationDelete(k); err != nil {
+				w.log.Error("failed to handle relation delete", zap.String("relation", k), zap.Error(err))
+			}
+		}
+	}
+
+	w.fingerprint = fingerprint
+	return nil
+}
+
+// fetchColumns fills in the columns of the relations.
+func (w *Watcher) fetchColumns(ctx context.Context, relations map[string]Relation) error {
+	ids := make([]int32, 0, len(relations))
+	for _, r := range relations {
+		ids = append(ids, r.ID)
+	}
+
+	rows, err := w.rw.pool.Query(ctx, getColumnsSQL, ids)
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch columns from RisingWave")
 	}
@@ -202,8 +511,8 @@ func (w *Watcher) UpdateCache(ctx context.Context) error {
 			return errors.Wrap(err, "failed to scan relation row")
 		}
 
-		key := schema + "." + relationName
-		relation, exists := updatedRelations[key]
+		key := FormatName(schema, relationName)
+		relation, exists := relations[key]
 		if exists {
 			relation.Columns = append(relation.Columns, Column{
 				Name:         columnName,
@@ -212,30 +521,12 @@ func (w *Watcher) UpdateCache(ctx context.Context) error {
 				IsHidden:     isHidden,
 				isArray:      strings.HasSuffix(columnType, "[]"),
 			})
-			updatedRelations[key] = relation
+			relations[key] = relation
 		}
 	}
 
 	if rows.Err() != nil {
 		return errors.Wrap(rows.Err(), "error occurred during rows iteration")
 	}
-
-	w.mu.Lock()
-	for k, v := range updatedRelations {
-		w.lastKeyToDefs[k] = v.Definition
-		if err := w.onRelationUpdate(v); err != nil {
-			w.log.Error("failed to handle relation update", zap.String("relation", k), zap.Error(err))
-		}
-	}
-	for k := range w.lastKeyToDefs {
-		if _, exist := newlyFetched[k]; !exist {
-			w.log.Info("relation deleted", zap.String("relation", k))
-			delete(w.lastKeyToDefs, k)
-			if err := w.onRelationDelete(k); err != nil {
-				w.log.Error("failed to handle relation delete", zap.String("relation", k), zap.Error(err))
-			}
-		}
-	}
-	w.mu.Unlock()
 	return nil
 }
@@ -0,0 +1,20 @@
+package rw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependentsOf(t *testing.T) {
+	dependents := map[string][]string{
+		"public.clicks":         {"public.clicks_by_page", "public.sessions"},
+		"public.clicks_by_page": {"analytics.top_pages"},
+		"public.sessions":       {"analytics.top_pages"},
+	}
+
+	require.Equal(t, []string{"public.clicks_by_page", "public.sessions", "analytics.top_pages"},
+		dependentsOf(dependents, []string{"public.clicks"}))
+	require.Equal(t, []string{"analytics.top_pages"}, dependentsOf(dependents, []string{"public.sessions"}))
+	require.Empty(t, dependentsOf(dependents, []string{"analytics.top_pages"}))
+}
@@ -0,0 +1,156 @@
+package savedquery
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/rw"
+	"go.uber.org/zap"
+)
+
+const reloadInterval = 30 * time.Second
+
+// Registry holds the saved queries of the configuration and of the saved query table.
+type Registry struct {
+	rw    *rw.RisingWave
+	table string
+	log   *zap.Logger
+
+	static map[string]*Query
+
+	mu     sync.RWMutex
+	stored map[string]*Query
+}
+
+func NewRegistry(cfg *config.Config, rwc *rw.RisingWave, gctx *gctx.GlobalContext, log *zap.Logger) (*Registry, error) {
+	r := &Registry{
+		rw:     rwc,
+		log:    log.Named("savedquery"),
+		static: make(map[string]*Query, len(cfg.SavedQueries.Queries)),
+		stored: make(map[string]*Query),
+	}
+	for _, qc := range cfg.SavedQueries.Queries {
+		q, err := newQuery(qc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid saved query %s", qc.Name)
+		}
+		if _, ok := r.static[q.Name]; ok {
+			return nil, errors.Errorf("duplicate saved query %s", q.Name)
+		}
+		r.static[q.Name] = q
+	}
+
+	if cfg.SavedQueries.Table == "" {
+		return r, nil
+	}
+	table, err := rw.Identifier(cfg.SavedQueries.Table)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid saved query table")
+	}
+	r.table = table
+
+	ctx := gctx.Context()
+	if _, err := rwc.Pool().Exec(ctx, `CREATE TABLE IF NOT EXISTS `+r.table+` (
+		name        VARCHAR PRIMARY KEY,
+		sql         VARCHAR,
+		description VARCHAR,
+		params      JSONB,
+		max_age     INT,
+		keys        VARCHAR[]
+	)`); err != nil {
+		return nil, errors.Wrapf(err, "failed to create saved query table %s", cfg.SavedQueries.Table)
+	}
+	if err := r.reload(ctx); err != nil {
+		return nil, err
+	}
+	go r.run(ctx)
+	return r, nil
+}
+
+// Get returns the saved query with the given name.
+func (r *Registry) Get(name string) (*Query, bool) {
+	if q, ok := r.static[name]; ok {
+		return q, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	q, ok := r.stored[name]
+	return q, ok
+}
+
+func (r *Registry) run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reload(ctx); err != nil && ctx.Err() == nil {
+				r.log.Warn("failed to reload saved queries", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reload replaces the queries of the table. Invalid rows are skipped so that one bad definition does not take the
+// others down.
+func (r *Registry) reload(ctx context.Context) error {
+	rows, err := r.rw.Pool().Query(ctx, `SELECT name, sql, description, params, max_age, keys FROM `+r.table)
+	if err != nil {
+		return errors.Wrap(err, "failed to query saved queries")
+	}
+	defer rows.Close()
+
+	stored := make(map[string]*Query)
+	for rows.Next() {
+		var (
+			qc          config.SavedQuery
+			sql         *string
+			description *string
+			params      *string
+			maxAge      *int32
+		)
+		if err := rows.Scan(&qc.Name, &sql, &description, &params, &maxAge, &qc.Keys); err != nil {
+			return errors.Wrap(err, "failed to scan saved query")
+		}
+		if sql != nil {
+			qc.SQL = *sql
+		}
+		if description != nil {
+			qc.Description = *description
+		}
+		if maxAge != nil {
+			qc.MaxAge = int(*maxAge)
+		}
+		if params != nil {
+			if err := json.Unmarshal([]byte(*params), &qc.Params); err != nil {
+				r.log.Warn("skipping saved query with invalid params", zap.String("name", qc.Name), zap.Error(err))
+				continue
+			}
+		}
+
+		q, err := newQuery(qc)
+		if err != nil {
+			r.log.Warn("skipping invalid saved query", zap.String("name", qc.Name), zap.Error(err))
+			continue
+		}
+		if _, ok := r.static[q.Name]; ok {
+			continue
+		}
+		stored[q.Name] = q
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "error occurred during rows iteration")
+	}
+
+	r.mu.Lock()
+	r.stored = stored
+	r.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,263 @@
+package savedquery
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/statement"
+)
+
+const (
+	TypeString    = "string"
+	TypeInteger   = "integer"
+	TypeNumber    = "number"
+	TypeBoolean   = "boolean"
+	TypeDate      = "date"
+	TypeTimestamp = "timestamp"
+)
+
+var (
+	// ErrInvalidParam is returned when the parameters of a request do not match the declared ones.
+	ErrInvalidParam = errors.New("invalid parameter")
+
+	validName  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	validTypes = map[string]bool{
+		TypeString:    true,
+		TypeInteger:   true,
+		TypeNumber:    true,
+		TypeBoolean:   true,
+		TypeDate:      true,
+		TypeTimestamp: true,
+	}
+)
+
+type Param struct {
+	config.SavedQueryParam
+
+	elem  string
+	array bool
+}
+
+// Query is a named read-only statement with typed parameters, published at /v1/queries/{name}.
+type Query struct {
+	Name        string
+	SQL         string
+	Description string
+	Params      []Param
+	MaxAge      int
+
+	keys map[string]bool
+}
+
+func newQuery(cfg config.SavedQuery) (*Query, error) {
+	if !validName.MatchString(cfg.Name) {
+		return nil, errors.Errorf("invalid name %q, only letters, digits, _ and - are allowed", cfg.Name)
+	}
+
+	stmts, err := statement.Split(cfg.SQL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse sql")
+	}
+	if len(stmts) != 1 || !stmts[0].IsReadOnly() {
+		return nil, errors.New("sql must be a single read-only statement")
+	}
+
+	q := &Query{
+		Name:        cfg.Name,
+		SQL:         stmts[0].SQL,
+		Description: cfg.Description,
+		MaxAge:      cfg.MaxAge,
+	}
+	if len(cfg.Keys) > 0 {
+		q.keys = make(map[string]bool, len(cfg.Keys))
+		for _, k := range cfg.Keys {
+			q.keys[k] = true
+		}
+	}
+
+	seen := make(map[string]bool, len(cfg.Params))
+	for _, pc := range cfg.Params {
+		if pc.Name == "" {
+			return nil, errors.New("parameter name is required")
+		}
+		if seen[pc.Name] {
+			return nil, errors.Errorf("duplicate parameter %s", pc.Name)
+		}
+		seen[pc.Name] = true
+
+		p := Param{SavedQueryParam: pc, elem: pc.Type}
+		if p.elem == "" {
+			p.elem = TypeString
+		}
+		p.elem, p.array = strings.CutSuffix(p.elem, "[]")
+		if !validTypes[p.elem] {
+			return nil, errors.Errorf("unknown type %q of parameter %s", pc.Type, pc.Name)
+		}
+		if pc.Default != nil {
+			if _, err := p.bind(p.defaults()); err != nil {
+				return nil, errors.Wrapf(err, "invalid default of parameter %s", pc.Name)
+			}
+		}
+		q.Params = append(q.Params, p)
+	}
+	return q, nil
+}
+
+// Allows reports whether the API key can run the query.
+func (q *Query) Allows(apiKeyID string) bool {
+	return q.keys == nil || q.keys[apiKeyID]
+}
+
+// Public reports whether anyone can run the query, so that its result can be cached by shared caches.
+func (q *Query) Public() bool {
+	return q.keys == nil
+}
+
+// Bind validates the query string arguments of a request and returns the statement arguments in the order the
+// parameters are declared. Dates and timestamps are validated and passed as text.
+func (q *Query) Bind(args map[string][]string) ([]any, error) {
+	for name := range args {
+		if !q.hasParam(name) {
+			return nil, errors.Wrapf(ErrInvalidParam, "unknown parameter %s", name)
+		}
+	}
+
+	ret := make([]any, len(q.Params))
+	for i, p := range q.Params {
+		values := args[p.Name]
+		if len(values) == 0 {
+			switch {
+			case p.Default != nil:
+				values = p.defaults()
+			case p.Required:
+				return nil, errors.Wrapf(ErrInvalidParam, "parameter %s is required", p.Name)
+			default:
+				continue
+			}
+		}
+		v, err := p.bind(values)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidParam, "parameter %s: %v", p.Name, err)
+		}
+		ret[i] = v
+	}
+	return ret, nil
+}
+
+func (q *Query) hasParam(name string) bool {
+	for _, p := range q.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaults returns the default values of the parameter, the default of an array parameter is a comma separated list.
+func (p *Param) defaults() []string {
+	if p.array {
+		return strings.Split(*p.Default, ",")
+	}
+	return []string{*p.Default}
+}
+
+// bind converts the values of the parameter. Array parameters are given by repeating the parameter.
+func (p *Param) bind(values []string) (any, error) {
+	if !p.array {
+		if len(values) > 1 {
+			return nil, errors.New("expected a single value")
+		}
+		return p.convert(values[0])
+	}
+
+	var (
+		ints   []int64
+		floats []float64
+		bools  []bool
+		strs   []string
+	)
+	for _, s := range values {
+		v, err := p.convert(s)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case int64:
+			ints = append(ints, v)
+		case float64:
+			floats = append(floats, v)
+		case bool:
+			bools = append(bools, v)
+		case string:
+			strs = append(strs, v)
+		}
+	}
+	switch p.elem {
+	case TypeInteger:
+		return ints, nil
+	case TypeNumber:
+		return floats, nil
+	case TypeBoolean:
+		return bools, nil
+	}
+	return strs, nil
+}
+
+func (p *Param) convert(s string) (any, error) {
+	switch p.elem {
+	case TypeInteger:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("%q is not an integer", s)
+		}
+		return i, p.checkRange(float64(i))
+	case TypeNumber:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.Errorf("%q is not a number", s)
+		}
+		return f, p.checkRange(f)
+	case TypeBoolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.Errorf("%q is not a boolean", s)
+		}
+		return b, nil
+	case TypeDate:
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return nil, errors.Errorf("%q is not a date, expected YYYY-MM-DD", s)
+		}
+		return s, nil
+	case TypeTimestamp:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, errors.Errorf("%q is not an RFC 3339 timestamp", s)
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}
+
+	if len(p.Enum) > 0 {
+		for _, e := range p.Enum {
+			if s == e {
+				return s, nil
+			}
+		}
+		return nil, errors.Errorf("%q is not one of %s", s, strings.Join(p.Enum, ", "))
+	}
+	return s, nil
+}
+
+func (p *Param) checkRange(f float64) error {
+	if p.Min != nil && f < *p.Min {
+		return errors.Errorf("%v is less than %v", f, *p.Min)
+	}
+	if p.Max != nil && f > *p.Max {
+		return errors.Errorf("%v is greater than %v", f, *p.Max)
+	}
+	return nil
+}
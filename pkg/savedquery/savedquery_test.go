@@ -0,0 +1,74 @@
+package savedquery
+
+import (
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestNewQuery(t *testing.T) {
+	testCases := []struct {
+		cfg config.SavedQuery
+		err bool
+	}{
+		{config.SavedQuery{Name: "top_pages", SQL: "SELECT * FROM page_views_mv LIMIT $1;"}, false},
+		{config.SavedQuery{Name: "top pages", SQL: "SELECT 1"}, true},
+		{config.SavedQuery{Name: "drop", SQL: "DROP TABLE t"}, true},
+		{config.SavedQuery{Name: "two", SQL: "SELECT 1; SELECT 2"}, true},
+		{config.SavedQuery{Name: "q", SQL: "SELECT $1", Params: []config.SavedQueryParam{{Name: "a", Type: "uuid"}}}, true},
+		{config.SavedQuery{Name: "q", SQL: "SELECT $1, $2", Params: []config.SavedQueryParam{{Name: "a"}, {Name: "a"}}}, true},
+		{config.SavedQuery{Name: "q", SQL: "SELECT $1", Params: []config.SavedQueryParam{{Name: "a", Type: "integer", Default: ptr("ten")}}}, true},
+		{config.SavedQuery{Name: "q", SQL: "SELECT $1", Params: []config.SavedQueryParam{{Name: "a", Type: "integer[]", Default: ptr("1,2")}}}, false},
+	}
+	for _, tc := range testCases {
+		_, err := newQuery(tc.cfg)
+		if tc.err {
+			require.Error(t, err, tc.cfg)
+		} else {
+			require.NoError(t, err, tc.cfg)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	q, err := newQuery(config.SavedQuery{
+		Name: "top_pages",
+		SQL:  "SELECT * FROM page_views_mv WHERE day >= $1 AND device = ANY($2) AND ($3 IS NULL OR page_url = $3) LIMIT $4",
+		Params: []config.SavedQueryParam{
+			{Name: "since", Type: "timestamp", Required: true},
+			{Name: "device", Type: "string[]", Default: ptr("desktop,mobile"), Enum: []string{"desktop", "mobile", "tablet"}},
+			{Name: "page"},
+			{Name: "limit", Type: "integer", Default: ptr("10"), Min: ptr(1.0), Max: ptr(100.0)},
+		},
+		Keys: []string{"dashboard"},
+	})
+	require.NoError(t, err)
+	require.True(t, q.Allows("dashboard"))
+	require.False(t, q.Allows(""))
+	require.False(t, q.Public())
+
+	args, err := q.Bind(map[string][]string{"since": {"2024-01-15T10:30:00+01:00"}})
+	require.NoError(t, err)
+	require.Equal(t, []any{"2024-01-15T09:30:00Z", []string{"desktop", "mobile"}, nil, int64(10)}, args)
+
+	args, err = q.Bind(map[string][]string{"since": {"2024-01-15T00:00:00Z"}, "device": {"tablet"}, "page": {"/"}, "limit": {"5"}})
+	require.NoError(t, err)
+	require.Equal(t, []any{"2024-01-15T00:00:00Z", []string{"tablet"}, "/", int64(5)}, args)
+
+	for _, args := range []map[string][]string{
+		{},
+		{"since": {"yesterday"}},
+		{"since": {"2024-01-15T00:00:00Z"}, "limit": {"500"}},
+		{"since": {"2024-01-15T00:00:00Z"}, "limit": {"5", "6"}},
+		{"since": {"2024-01-15T00:00:00Z"}, "device": {"watch"}},
+		{"since": {"2024-01-15T00:00:00Z"}, "offset": {"5"}},
+	} {
+		_, err := q.Bind(args)
+		require.ErrorIs(t, err, ErrInvalidParam, args)
+	}
+}
@@ -0,0 +1,64 @@
+package statement
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidName is returned when a name is not a possibly qualified identifier.
+var ErrInvalidName = errors.New("invalid name")
+
+// ParseIdentifier splits a possibly qualified name such as analytics."Click Events" into its parts. Unquoted parts are
+// folded to lower case, quoted parts are kept as written.
+func ParseIdentifier(name string) ([]string, error) {
+	var (
+		parts []string
+		l     = lexer{src: name}
+	)
+	for {
+		tok, _, ok, err := l.next()
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidName, "%q: %v", name, err)
+		}
+		if !ok || !isIdent(tok) || (tok.Type == TokenQuotedIdent && tok.Text == "") {
+			return nil, errors.Wrapf(ErrInvalidName, "%q", name)
+		}
+		parts = append(parts, identName(tok))
+
+		tok, _, ok, err = l.next()
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidName, "%q: %v", name, err)
+		}
+		if !ok {
+			return parts, nil
+		}
+		if !isPunct(tok, ".") {
+			return nil, errors.Wrapf(ErrInvalidName, "%q", name)
+		}
+	}
+}
+
+// QuoteIdent returns an identifier as it is written in a name. Identifiers that would not parse back to themselves
+// unquoted are double-quoted, so that joining the parts of a name with dots is unambiguous.
+func QuoteIdent(ident string) string {
+	if isPlainIdent(ident) {
+		return ident
+	}
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func isPlainIdent(ident string) bool {
+	if ident == "" || ident[0] == '$' {
+		return false
+	}
+	for i, r := range ident {
+		if r >= 'A' && r <= 'Z' || r >= 0x80 {
+			return false
+		}
+		if i == 0 && !isIdentStart(r) || !isIdentPart(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package statement
+
+import (
+	"strings"
+)
+
+// Normalized returns the statement with comments removed, whitespace collapsed and unquoted identifiers and keywords
+// folded to lower case, so that statements that only differ in formatting have the same text.
+func (s *Statement) Normalized() string {
+	var sb strings.Builder
+	for i, t := range s.Tokens {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		switch t.Type {
+		case TokenWord:
+			sb.WriteString(strings.ToLower(t.Text))
+		case TokenQuotedIdent:
+			sb.WriteString(`"` + strings.ReplaceAll(t.Text, `"`, `""`) + `"`)
+		case TokenString:
+			sb.WriteString(`'` + strings.ReplaceAll(t.Text, `'`, `''`) + `'`)
+		default:
+			sb.WriteString(t.Text)
+		}
+	}
+	return sb.String()
+}
+
+// Names returns the distinct possibly qualified identifiers of the statement, joined as by QuoteIdent, which include
+// the relations it reads.
+// Keywords and column names are returned as well, callers match the names against known relations.
+func (s *Statement) Names() []string {
+	var (
+		ret  []string
+		seen = make(map[string]bool)
+	)
+	for i := 0; i < len(s.Tokens); {
+		parts, n := qualifiedName(s.Tokens[i:])
+		if n == 0 {
+			i++
+			continue
+		}
+		i += n
+		name := joinName(parts)
+		if !seen[name] {
+			seen[name] = true
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
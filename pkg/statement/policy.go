@@ -0,0 +1,126 @@
+package statement
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/risingwavelabs/events-api/pkg/config"
+)
+
+const (
+	ModeReadWrite = "readwrite"
+	ModeReadOnly  = "readonly"
+)
+
+// Violation is returned when a statement is rejected by the policy.
+type Violation struct {
+	// Index is the position of the rejected statement in the script, starting from 0.
+	Index  int
+	Kind   string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("statement %d (%s) is not allowed: %s", v.Index+1, v.Kind, v.Reason)
+}
+
+type rules struct {
+	mode  string
+	allow []string
+	deny  []string
+}
+
+func (r *rules) unrestricted() bool {
+	return r.mode == ModeReadWrite && len(r.allow) == 0 && len(r.deny) == 0
+}
+
+func (r *rules) check(i int, stmt *Statement) error {
+	if r.mode == ModeReadOnly && !stmt.IsReadOnly() {
+		return &Violation{Index: i, Kind: stmt.Kind, Reason: "only read-only statements are allowed"}
+	}
+	if len(r.allow) > 0 {
+		allowed := false
+		for _, kind := range r.allow {
+			if stmt.Matches(kind) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &Violation{Index: i, Kind: stmt.Kind, Reason: "statement kind is not in the allow list"}
+		}
+	}
+	for _, kind := range r.deny {
+		if stmt.Matches(kind) {
+			return &Violation{Index: i, Kind: stmt.Kind, Reason: fmt.Sprintf("%s statements are denied", kind)}
+		}
+	}
+	return nil
+}
+
+// Policy decides which statements can be executed through the SQL endpoint.
+type Policy struct {
+	defaults rules
+	keys     map[string]rules
+}
+
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	defaults, err := toRules(cfg.SQL.SQLPolicy, rules{mode: ModeReadWrite})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sql policy")
+	}
+
+	p := &Policy{
+		defaults: defaults,
+		keys:     make(map[string]rules, len(cfg.SQL.Keys)),
+	}
+	for id, override := range cfg.SQL.Keys {
+		r, err := toRules(override, defaults)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid sql policy for api key %s", id)
+		}
+		p.keys[id] = r
+	}
+	return p, nil
+}
+
+// toRules applies the configured policy on top of the base rules. Unset fields are inherited from the base.
+func toRules(cfg config.SQLPolicy, base rules) (rules, error) {
+	r := base
+	switch cfg.Mode {
+	case "":
+	case ModeReadOnly, ModeReadWrite:
+		r.mode = cfg.Mode
+	default:
+		return rules{}, errors.Errorf("unknown mode %q", cfg.Mode)
+	}
+	if cfg.Allow != nil {
+		r.allow = cfg.Allow
+	}
+	if cfg.Deny != nil {
+		r.deny = cfg.Deny
+	}
+	return r, nil
+}
+
+// Check classifies the script and returns a *Violation for the first statement the API key is not allowed to run.
+func (p *Policy) Check(apiKeyID string, sql string) error {
+	r, ok := p.keys[apiKeyID]
+	if !ok {
+		r = p.defaults
+	}
+	if r.unrestricted() {
+		return nil
+	}
+
+	stmts, err := Split(sql)
+	if err != nil {
+		return errors.Wrap(err, "failed to classify statements")
+	}
+	for i := range stmts {
+		if err := r.check(i, &stmts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package statement
+
+import "strings"
+
+// Source is the table the rows of a SELECT statement are read from.
+type Source struct {
+	// Table is the possibly schema-qualified name of the table, with unquoted identifiers case-folded and the
+	// others quoted as by QuoteIdent.
+	Table string
+
+	// Star is true if the select list contains * or <table>.*, all table columns are then output under their name.
+	Star bool
+
+	// Columns maps the name of the output columns that are plain column references to the referenced table column.
+	Columns map[string]string
+}
+
+// fromEnd are the keywords that may follow the table of a FROM clause whose rows are rows of the table.
+var fromEnd = map[string]bool{
+	"WHERE":  true,
+	"ORDER":  true,
+	"LIMIT":  true,
+	"OFFSET": true,
+	"FETCH":  true,
+}
+
+// notRowPreserving are the keywords that make the result rows not map one to one to rows of a table.
+var notRowPreserving = map[string]bool{
+	"JOIN":      true,
+	"GROUP":     true,
+	"HAVING":    true,
+	"UNION":     true,
+	"INTERSECT": true,
+	"EXCEPT":    true,
+}
+
+// Source returns the single table the rows of a SELECT statement come from. ok is false for any other statement,
+// and for selects with joins, subqueries in FROM, grouping, DISTINCT or set operations.
+func (s *Statement) Source() (*Source, bool) {
+	tokens := s.Tokens
+	if s.Kind != "SELECT" || tokens[0].Upper() != "SELECT" {
+		return nil, false
+	}
+	tokens = tokens[1:]
+	if len(tokens) > 0 && tokens[0].Upper() == "ALL" {
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && tokens[0].Upper() == "DISTINCT" {
+		return nil, false
+	}
+
+	// split the select list into its items
+	var (
+		items [][]Token
+		item  []Token
+		depth int
+		from  = -1
+	)
+	for i, t := range tokens {
+		if t.Type == TokenPunct {
+			switch t.Text {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				depth--
+			case ",":
+				if depth == 0 {
+					items = append(items, item)
+					item = nil
+					continue
+				}
+			}
+		}
+		if depth == 0 && t.Upper() == "FROM" {
+			from = i
+			break
+		}
+		item = append(item, t)
+	}
+	if from < 0 {
+		return nil, false
+	}
+	items = append(items, item)
+
+	rest := tokens[from+1:]
+	parts, n := qualifiedName(rest)
+	if n == 0 {
+		return nil, false
+	}
+	rest = rest[n:]
+	table := joinName(parts)
+
+	// the table may be aliased, the alias is only a qualifier of the select list
+	qualifiers := map[string]bool{table: true, QuoteIdent(parts[len(parts)-1]): true}
+	if len(rest) > 0 && rest[0].Upper() == "AS" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && isIdent(rest[0]) && !fromEnd[rest[0].Upper()] && !notRowPreserving[rest[0].Upper()] {
+		qualifiers[QuoteIdent(identName(rest[0]))] = true
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && !fromEnd[rest[0].Upper()] {
+		return nil, false
+	}
+	depth = 0
+	for _, t := range rest {
+		if t.Type == TokenPunct {
+			switch t.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+		}
+		if depth == 0 && notRowPreserving[t.Upper()] {
+			return nil, false
+		}
+	}
+
+	src := &Source{Table: table, Columns: map[string]string{}}
+	for _, item := range items {
+		if qualified, n := qualifiedName(item); n > 0 && n == len(item)-2 && isPunct(item[n], ".") && isPunct(item[n+1], "*") {
+			src.Star = src.Star || qualifiers[joinName(qualified)]
+			continue
+		}
+		if len(item) == 1 && isPunct(item[0], "*") {
+			src.Star = true
+			continue
+		}
+
+		ref, n := qualifiedName(item)
+		if n == 0 {
+			continue
+		}
+		col := ref[len(ref)-1]
+		if len(ref) > 1 && !qualifiers[joinName(ref[:len(ref)-1])] {
+			continue
+		}
+		alias := item[n:]
+		if len(alias) > 0 && alias[0].Upper() == "AS" {
+			alias = alias[1:]
+		}
+		switch {
+		case len(alias) == 0:
+			src.Columns[col] = col
+		case len(alias) == 1 && isIdent(alias[0]):
+			src.Columns[identName(alias[0])] = col
+		}
+	}
+	return src, true
+}
+
+// qualifiedName reads a possibly qualified identifier and returns its parts along with the number of tokens it spans.
+func qualifiedName(tokens []Token) ([]string, int) {
+	var parts []string
+	i := 0
+	for i < len(tokens) && isIdent(tokens[i]) {
+		parts = append(parts, identName(tokens[i]))
+		i++
+		if i+1 < len(tokens) && isPunct(tokens[i], ".") && isIdent(tokens[i+1]) {
+			i++
+			continue
+		}
+		break
+	}
+	return parts, i
+}
+
+// joinName joins the parts of a qualified name by dots, quoting them as by QuoteIdent.
+func joinName(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, p := range parts {
+		quoted[i] = QuoteIdent(p)
+	}
+	return strings.Join(quoted, ".")
+}
+
+func isIdent(t Token) bool {
+	return t.Type == TokenWord || t.Type == TokenQuotedIdent
+}
+
+func isPunct(t Token, text string) bool {
+	return t.Type == TokenPunct && t.Text == text
+}
+
+// identName returns the name an identifier refers to, unquoted identifiers are folded to lower case.
+func identName(t Token) string {
+	if t.Type == TokenQuotedIdent {
+		return t.Text
+	}
+	return strings.ToLower(t.Text)
+}
@@ -0,0 +1,392 @@
+package statement
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnterminated = errors.New("unterminated quoted string, identifier or comment")
+
+type TokenType int
+
+const (
+	TokenWord TokenType = iota
+	TokenQuotedIdent
+	TokenString
+	TokenNumber
+	TokenParam
+	TokenPunct
+)
+
+type Token struct {
+	Type TokenType
+	// Text is the raw text of the token. Quoted identifiers and strings are unquoted.
+	Text string
+}
+
+// Upper returns the upper-cased text of a word, or an empty string for any other token.
+func (t Token) Upper() string {
+	if t.Type != TokenWord {
+		return ""
+	}
+	return strings.ToUpper(t.Text)
+}
+
+type Statement struct {
+	// SQL is the text of the statement without the trailing semicolon.
+	SQL string
+
+	// Kind is the leading keywords of the statement, e.g. "SELECT", "CREATE MATERIALIZED VIEW" or "ALTER SYSTEM".
+	Kind string
+
+	Tokens []Token
+}
+
+// IsReadOnly reports whether the statement only reads data.
+func (s *Statement) IsReadOnly() bool {
+	switch s.Kind {
+	case "SELECT", "SHOW", "DESCRIBE", "EXPLAIN", "VALUES":
+		return true
+	}
+	return false
+}
+
+// IsDDL reports whether the statement creates, alters or drops an object.
+func (s *Statement) IsDDL() bool {
+	verb, _, _ := strings.Cut(s.Kind, " ")
+	return verb == "CREATE" || verb == "ALTER" || verb == "DROP"
+}
+
+// HasDDL reports whether the script has a DDL statement. Scripts that cannot be split are assumed to have one.
+func HasDDL(sql string) bool {
+	stmts, err := Split(sql)
+	if err != nil {
+		return true
+	}
+	for i := range stmts {
+		if stmts[i].IsDDL() {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the kind of the statement is the given kind or a more specific one,
+// e.g. "DROP TABLE" matches "DROP".
+func (s *Statement) Matches(kind string) bool {
+	kind = strings.ToUpper(strings.Join(strings.Fields(kind), " "))
+	return s.Kind == kind || strings.HasPrefix(s.Kind, kind+" ")
+}
+
+// ObjectName returns the name of the object created by a CREATE statement, possibly schema-qualified, with
+// unquoted identifiers case-folded and the others quoted as by QuoteIdent.
+func (s *Statement) ObjectName() (string, bool) {
+	words := strings.Fields(s.Kind)
+	if len(words) < 2 || words[0] != "CREATE" {
+		return "", false
+	}
+	i := 1
+	for i < len(s.Tokens) && modifiers[s.Tokens[i].Upper()] {
+		i++
+	}
+	i += len(words) - 1
+	if hasWords(s.Tokens[min(i, len(s.Tokens)):], []string{"IF", "NOT", "EXISTS"}) {
+		i += 3
+	}
+	if i >= len(s.Tokens) {
+		return "", false
+	}
+	parts, n := qualifiedName(s.Tokens[i:])
+	return joinName(parts), n > 0
+}
+
+var objectTypes = [][]string{
+	{"MATERIALIZED", "VIEW"},
+	{"MATERIALIZED", "SOURCE"},
+	{"TABLE"},
+	{"VIEW"},
+	{"SOURCE"},
+	{"SINK"},
+	{"INDEX"},
+	{"FUNCTION"},
+	{"AGGREGATE"},
+	{"SCHEMA"},
+	{"DATABASE"},
+	{"USER"},
+	{"CONNECTION"},
+	{"SECRET"},
+	{"SUBSCRIPTION"},
+	{"SYSTEM"},
+	{"PARALLELISM"},
+}
+
+// modifiers may appear between the verb and the object type, e.g. CREATE OR REPLACE VIEW.
+var modifiers = map[string]bool{
+	"OR":        true,
+	"REPLACE":   true,
+	"TEMP":      true,
+	"TEMPORARY": true,
+	"UNIQUE":    true,
+}
+
+// Split splits a SQL script into statements and classifies each of them. Comments and empty statements are dropped.
+func Split(sql string) ([]Statement, error) {
+	var (
+		ret    []Statement
+		tokens []Token
+		start  = 0
+	)
+
+	flush := func(end int) {
+		if len(tokens) > 0 {
+			ret = append(ret, Statement{
+				SQL:    strings.TrimSpace(sql[start:end]),
+				Kind:   kindOf(tokens),
+				Tokens: tokens,
+			})
+		}
+		tokens = nil
+		start = end + 1
+	}
+
+	l := lexer{src: sql}
+	for {
+		tok, pos, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if tok.Type == TokenPunct && tok.Text == ";" {
+			flush(pos)
+			continue
+		}
+		if len(tokens) == 0 {
+			start = pos
+		}
+		tokens = append(tokens, tok)
+	}
+	flush(len(sql))
+
+	return ret, nil
+}
+
+func kindOf(tokens []Token) string {
+	verb := tokens[0].Upper()
+	switch verb {
+	case "":
+		return ""
+	case "CREATE", "DROP", "ALTER":
+		i := 1
+		for i < len(tokens) && modifiers[tokens[i].Upper()] {
+			i++
+		}
+		for _, typ := range objectTypes {
+			if hasWords(tokens[i:], typ) {
+				return verb + " " + strings.Join(typ, " ")
+			}
+		}
+		return verb
+	case "WITH":
+		// the kind of a statement with common table expressions is the kind of its main statement
+		depth := 0
+		for _, t := range tokens[1:] {
+			if t.Type == TokenPunct {
+				switch t.Text {
+				case "(":
+					depth++
+				case ")":
+					depth--
+				}
+				continue
+			}
+			if depth == 0 {
+				switch u := t.Upper(); u {
+				case "SELECT", "INSERT", "UPDATE", "DELETE", "VALUES":
+					return u
+				}
+			}
+		}
+		return verb
+	}
+	return verb
+}
+
+func hasWords(tokens []Token, words []string) bool {
+	if len(tokens) < len(words) {
+		return false
+	}
+	for i, w := range words {
+		if tokens[i].Upper() != w {
+			return false
+		}
+	}
+	return true
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+// next returns the next token and its starting offset, skipping whitespace and comments.
+func (l *lexer) next() (Token, int, bool, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "--"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end + 1
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			if err := l.skipBlockComment(); err != nil {
+				return Token{}, 0, false, err
+			}
+		default:
+			start := l.pos
+			tok, err := l.token()
+			return tok, start, true, err
+		}
+	}
+	return Token{}, 0, false, nil
+}
+
+func (l *lexer) skipBlockComment() error {
+	depth := 0
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			depth++
+			l.pos += 2
+		case strings.HasPrefix(l.src[l.pos:], "*/"):
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return nil
+			}
+		default:
+			l.pos++
+		}
+	}
+	return ErrUnterminated
+}
+
+func (l *lexer) token() (Token, error) {
+	c := l.src[l.pos]
+	switch {
+	case c == '\'':
+		s, err := l.quoted('\'', false)
+		return Token{Type: TokenString, Text: s}, err
+	case (c == 'E' || c == 'e') && l.peek(1) == '\'':
+		l.pos++
+		s, err := l.quoted('\'', true)
+		return Token{Type: TokenString, Text: s}, err
+	case c == '"':
+		s, err := l.quoted('"', false)
+		return Token{Type: TokenQuotedIdent, Text: s}, err
+	case c == '$' && isDigit(l.peek(1)):
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		return Token{Type: TokenParam, Text: l.src[start:l.pos]}, nil
+	case c == '$':
+		if tag, ok := l.dollarTag(); ok {
+			return l.dollarQuoted(tag)
+		}
+		l.pos++
+		return Token{Type: TokenPunct, Text: "$"}, nil
+	case isIdentStart(rune(c)) || c >= 0x80:
+		start := l.pos
+		for l.pos < len(l.src) && (isIdentPart(rune(l.src[l.pos])) || l.src[l.pos] >= 0x80) {
+			l.pos++
+		}
+		return Token{Type: TokenWord, Text: l.src[start:l.pos]}, nil
+	case isDigit(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.' || l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+			l.pos++
+		}
+		return Token{Type: TokenNumber, Text: l.src[start:l.pos]}, nil
+	default:
+		l.pos++
+		return Token{Type: TokenPunct, Text: string(c)}, nil
+	}
+}
+
+func (l *lexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+// quoted consumes a quoted string or identifier where the quote is escaped by doubling it.
+func (l *lexer) quoted(q byte, backslash bool) (string, error) {
+	var sb strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case backslash && c == '\\' && l.pos+1 < len(l.src):
+			sb.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+		case c == q && l.peek(1) == q:
+			sb.WriteByte(q)
+			l.pos += 2
+		case c == q:
+			l.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+			l.pos++
+		}
+	}
+	return "", ErrUnterminated
+}
+
+func (l *lexer) dollarTag() (string, bool) {
+	end := strings.IndexByte(l.src[l.pos+1:], '$')
+	if end < 0 {
+		return "", false
+	}
+	tag := l.src[l.pos : l.pos+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !isIdentPart(r) {
+			return "", false
+		}
+	}
+	return tag, true
+}
+
+func (l *lexer) dollarQuoted(tag string) (Token, error) {
+	l.pos += len(tag)
+	end := strings.Index(l.src[l.pos:], tag)
+	if end < 0 {
+		return Token{}, ErrUnterminated
+	}
+	s := l.src[l.pos : l.pos+end]
+	l.pos += end + len(tag)
+	return Token{Type: TokenString, Text: s}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
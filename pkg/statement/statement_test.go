@@ -0,0 +1,198 @@
+package statement
+
+import (
+	"testing"
+
+	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func kinds(t *testing.T, sql string) []string {
+	stmts, err := Split(sql)
+	require.NoError(t, err)
+	ret := []string{}
+	for _, s := range stmts {
+		ret = append(ret, s.Kind)
+	}
+	return ret
+}
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		sql   string
+		kinds []string
+	}{
+		{"select 1", []string{"SELECT"}},
+		{"SELECT 1; ; -- DROP TABLE t;\n", []string{"SELECT"}},
+		{"SELECT ';DROP TABLE t;'; show tables", []string{"SELECT", "SHOW"}},
+		{"/* DROP /* nested */ TABLE */ DESCRIBE t", []string{"DESCRIBE"}},
+		{"SELECT $$ ; DROP $$, $tag$ ; $$ ; $tag$", []string{"SELECT"}},
+		{"SELECT E'\\'; DROP TABLE t'", []string{"SELECT"}},
+		{`SELECT "a;b" FROM t WHERE id = $1`, []string{"SELECT"}},
+		{"create materialized view mv as select 1", []string{"CREATE MATERIALIZED VIEW"}},
+		{"CREATE OR REPLACE VIEW v AS SELECT 1", []string{"CREATE VIEW"}},
+		{"CREATE SINK s FROM mv WITH (connector = 'blackhole')", []string{"CREATE SINK"}},
+		{"DROP TABLE IF EXISTS t; ALTER SYSTEM SET x = 1", []string{"DROP TABLE", "ALTER SYSTEM"}},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", []string{"SELECT"}},
+		{"WITH x AS (SELECT 1) DELETE FROM t WHERE id IN (SELECT * FROM x)", []string{"DELETE"}},
+		{"INSERT INTO t VALUES (1); FLUSH", []string{"INSERT", "FLUSH"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sql, func(t *testing.T) {
+			require.Equal(t, tc.kinds, kinds(t, tc.sql))
+		})
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	for sql, name := range map[string]string{
+		"CREATE MATERIALIZED VIEW IF NOT EXISTS Analytics.\"PageViews\" AS SELECT 1": `analytics."PageViews"`,
+		"create or replace view v as select 1":                                       "v",
+		"CREATE INDEX idx ON t (id)":                                                 "idx",
+		"CREATE TABLE":                                                               "",
+		"SELECT 1":                                                                   "",
+	} {
+		stmts, err := Split(sql)
+		require.NoError(t, err)
+		got, _ := stmts[0].ObjectName()
+		require.Equal(t, name, got, sql)
+	}
+}
+
+func TestSplitUnterminated(t *testing.T) {
+	for _, sql := range []string{"SELECT 'abc", `SELECT "abc`, "SELECT 1 /* abc", "SELECT $$abc"} {
+		_, err := Split(sql)
+		require.ErrorIs(t, err, ErrUnterminated, sql)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := NewPolicy(&config.Config{
+		SQL: config.SQL{
+			SQLPolicy: config.SQLPolicy{
+				Mode: ModeReadOnly,
+			},
+			Keys: map[string]config.SQLPolicy{
+				"admin": {
+					Mode: ModeReadWrite,
+					Deny: []string{"DROP", "ALTER SYSTEM"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, p.Check("", "SELECT * FROM t; SHOW TABLES"))
+
+	err = p.Check("", "SELECT 1; DROP TABLE t")
+	var v *Violation
+	require.ErrorAs(t, err, &v)
+	require.Equal(t, 1, v.Index)
+	require.Equal(t, "DROP TABLE", v.Kind)
+
+	require.NoError(t, p.Check("admin", "CREATE TABLE t (id INT)"))
+	require.ErrorAs(t, p.Check("admin", "DROP MATERIALIZED VIEW mv"), &v)
+	require.ErrorAs(t, p.Check("admin", "alter system set x = 1"), &v)
+}
+
+func TestSource(t *testing.T) {
+	testCases := []struct {
+		sql     string
+		table   string
+		star    bool
+		columns map[string]string
+	}{
+		{"SELECT * FROM t", "t", true, map[string]string{}},
+		{"SELECT id, x.name AS n, \"Val\" v, count(*) c, id + 1 FROM Public.T x WHERE id > 1 ORDER BY id LIMIT 10", "public.t", false, map[string]string{"id": "id", "n": "name", "v": "Val"}},
+		{"SELECT t.*, _row_id FROM t WHERE id IN (SELECT id FROM u GROUP BY id)", "t", true, map[string]string{"_row_id": "_row_id"}},
+	}
+
+	for _, tc := range testCases {
+		stmts, err := Split(tc.sql)
+		require.NoError(t, err)
+		src, ok := stmts[0].Source()
+		require.True(t, ok, tc.sql)
+		require.Equal(t, tc.table, src.Table, tc.sql)
+		require.Equal(t, tc.star, src.Star, tc.sql)
+		require.Equal(t, tc.columns, src.Columns, tc.sql)
+	}
+
+	for _, sql := range []string{
+		"SELECT DISTINCT id FROM t",
+		"SELECT a.id FROM t a JOIN u b ON a.id = b.id",
+		"SELECT * FROM t, u",
+		"SELECT * FROM (SELECT 1) s",
+		"SELECT kind, count(*) FROM t GROUP BY kind",
+		"SELECT id FROM t UNION SELECT id FROM u",
+		"SELECT 1",
+		"DELETE FROM t",
+	} {
+		stmts, err := Split(sql)
+		require.NoError(t, err)
+		_, ok := stmts[0].Source()
+		require.False(t, ok, sql)
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	stmts, err := Split("SELECT  *\n  FROM \"Clicks\" -- recent only\n WHERE ts > now() - INTERVAL '1 hour' AND note = 'it''s'")
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Equal(t, `select * from "Clicks" where ts > now ( ) - interval '1 hour' and note = 'it''s'`, stmts[0].Normalized())
+
+	other, err := Split(`select * from "Clicks" where TS > NOW() - interval '1 hour' and note = 'it''s'`)
+	require.NoError(t, err)
+	require.Equal(t, stmts[0].Normalized(), other[0].Normalized())
+}
+
+func TestNames(t *testing.T) {
+	stmts, err := Split(`SELECT c.page_url FROM analytics.clicks c JOIN "Users" u ON u.id = c.user_id`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"select", "c.page_url", "from", "analytics.clicks", "c", "join", `"Users"`, "u", "on", "u.id", "c.user_id"}, stmts[0].Names())
+}
+
+func TestHasDDL(t *testing.T) {
+	require.True(t, HasDDL("SELECT 1; CREATE TABLE t (id INT)"))
+	require.True(t, HasDDL("alter table t add column c int"))
+	require.True(t, HasDDL("DROP MATERIALIZED VIEW mv"))
+	require.False(t, HasDDL("INSERT INTO t VALUES (1); SELECT * FROM t"))
+	require.True(t, HasDDL("SELECT 'unterminated"))
+}
+
+func TestParseIdentifier(t *testing.T) {
+	testCases := []struct {
+		name  string
+		parts []string
+	}{
+		{"clicks", []string{"clicks"}},
+		{"Analytics.Clicks", []string{"analytics", "clicks"}},
+		{`analytics."Click Events"`, []string{"analytics", "Click Events"}},
+		{`"a.b"."say ""hi"""`, []string{"a.b", `say "hi"`}},
+	}
+	for _, tc := range testCases {
+		parts, err := ParseIdentifier(tc.name)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.parts, parts, tc.name)
+	}
+
+	for _, name := range []string{"", "a.", ".a", "a b", "a;b", `""`, `"a`, "a.b.c d", "1a"} {
+		_, err := ParseIdentifier(name)
+		require.ErrorIs(t, err, ErrInvalidName, name)
+	}
+}
+
+func TestQuoteIdent(t *testing.T) {
+	require.Equal(t, "clicks", QuoteIdent("clicks"))
+	require.Equal(t, "_t$1", QuoteIdent("_t$1"))
+	require.Equal(t, `"Clicks"`, QuoteIdent("Clicks"))
+	require.Equal(t, `"a.b"`, QuoteIdent("a.b"))
+	require.Equal(t, `"1a"`, QuoteIdent("1a"))
+	require.Equal(t, `"say ""hi"""`, QuoteIdent(`say "hi"`))
+
+	for _, ident := range []string{"clicks", "Clicks", "a.b", `say "hi"`, "click events"} {
+		parts, err := ParseIdentifier(QuoteIdent(ident))
+		require.NoError(t, err)
+		require.Equal(t, []string{ident}, parts)
+	}
+}
@@ -7,9 +7,15 @@ import (
 	"github.com/risingwavelabs/events-api/app"
 	"github.com/risingwavelabs/events-api/pkg/closer"
 	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/enrich"
 	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
 	"github.com/risingwavelabs/events-api/pkg/logger"
+	"github.com/risingwavelabs/events-api/pkg/ratelimit"
+	"github.com/risingwavelabs/events-api/pkg/querycache"
 	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/savedquery"
+	"github.com/risingwavelabs/events-api/pkg/statement"
 
 	"github.com/google/wire"
 )
@@ -19,12 +25,22 @@ func InitApp() (*app.App, error) {
 		logger.NewLogger,
 		app.NewApp,
 		app.NewHandler,
+		app.NewWebSocketHandler,
 		config.NewConfig,
 		gctx.New,
 		rw.NewRisingWave,
+		rw.NewSearchPath,
 		rw.NewBulkInsertManager,
 		rw.NewEventService,
+		rw.NewWatcher,
+		rw.NewSubscriber,
 		closer.NewCloserManager,
+		ratelimit.NewLimiter,
+		statement.NewPolicy,
+		savedquery.NewRegistry,
+		querycache.NewCache,
+		idempotency.NewStore,
+		enrich.NewGeoIP,
 	)
 	return nil, nil
 }
@@ -10,9 +10,15 @@ import (
 	"github.com/risingwavelabs/events-api/app"
 	"github.com/risingwavelabs/events-api/pkg/closer"
 	"github.com/risingwavelabs/events-api/pkg/config"
+	"github.com/risingwavelabs/events-api/pkg/enrich"
 	"github.com/risingwavelabs/events-api/pkg/gctx"
+	"github.com/risingwavelabs/events-api/pkg/idempotency"
 	"github.com/risingwavelabs/events-api/pkg/logger"
+	"github.com/risingwavelabs/events-api/pkg/querycache"
+	"github.com/risingwavelabs/events-api/pkg/ratelimit"
 	"github.com/risingwavelabs/events-api/pkg/rw"
+	"github.com/risingwavelabs/events-api/pkg/savedquery"
+	"github.com/risingwavelabs/events-api/pkg/statement"
 )
 
 // Injectors from wire.go:
@@ -27,8 +33,16 @@ func InitApp() (*app.App, error) {
 		return nil, err
 	}
 	globalContext := gctx.New(zapLogger)
+	searchPath, err := rw.NewSearchPath(configConfig)
+	if err != nil {
+		return nil, err
+	}
 	closerManager := closer.NewCloserManager(zapLogger)
-	risingWave, err := rw.NewRisingWave(configConfig, globalContext, closerManager, zapLogger)
+	risingWave, err := rw.NewRisingWave(configConfig, searchPath, globalContext, closerManager, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := rw.NewWatcher(configConfig, risingWave, searchPath, globalContext, zapLogger)
 	if err != nil {
 		return nil, err
 	}
@@ -36,11 +50,46 @@ func InitApp() (*app.App, error) {
 	if err != nil {
 		return nil, err
 	}
-	eventService, err := rw.NewEventService(globalContext, risingWave, zapLogger, bulkInsertManager, closerManager)
+	geoIP, err := enrich.NewGeoIP(configConfig, zapLogger, closerManager)
+	if err != nil {
+		return nil, err
+	}
+	eventService, err := rw.NewEventService(configConfig, searchPath, watcher, globalContext, zapLogger, bulkInsertManager, closerManager, geoIP)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := statement.NewPolicy(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := rw.NewSubscriber(configConfig, risingWave, searchPath, watcher, policy, globalContext, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := savedquery.NewRegistry(configConfig, risingWave, globalContext, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := querycache.NewCache(configConfig, searchPath, watcher, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	serverInterface, err := app.NewHandler(configConfig, searchPath, risingWave, eventService, watcher, policy, subscriber, registry, cache, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	limiter, err := ratelimit.NewLimiter(configConfig, globalContext, risingWave, closerManager, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	webSocketHandler := app.NewWebSocketHandler(globalContext, eventService, subscriber, limiter, cache, zapLogger)
+	store, err := idempotency.NewStore(configConfig, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	appApp, err := app.NewApp(configConfig, globalContext, zapLogger, serverInterface, webSocketHandler, limiter, eventService, store)
 	if err != nil {
 		return nil, err
 	}
-	serverInterface := app.NewHandler(risingWave, eventService)
-	appApp := app.NewApp(configConfig, globalContext, zapLogger, serverInterface)
 	return appApp, nil
 }